@@ -0,0 +1,160 @@
+package echotool
+
+import (
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	AccessFieldMethod    = "method"
+	AccessFieldPath      = "path"
+	AccessFieldRoute     = "route"
+	AccessFieldHandler   = "handler"
+	AccessFieldCode      = "code"
+	AccessFieldStatus    = "status"
+	AccessFieldLatency   = "latency"
+	AccessFieldBytes     = "bytes"
+	AccessFieldRequestID = "request_id"
+	AccessFieldClientIP  = "client_ip"
+	AccessFieldError     = "error"
+)
+
+var (
+	DefaultAccessFields = []string{
+		AccessFieldMethod,
+		AccessFieldPath,
+		AccessFieldHandler,
+		AccessFieldCode,
+		AccessFieldStatus,
+		AccessFieldLatency,
+		AccessFieldBytes,
+		AccessFieldRequestID,
+		AccessFieldError,
+	}
+
+	// DefaultAccessSkipPaths skips the metrics and health endpoints.
+	DefaultAccessSkipPaths = []string{"/metrics", "/health*"}
+)
+
+// AccessLogConfig is the config for the access log printed at the end of each request.
+// The custom values of Context are printed as well.
+type AccessLogConfig struct {
+	Fields []string
+	// SkipPaths are matched exactly, or by prefix if they end with "*".
+	SkipPaths []string
+	Skipper   func(echo.Context, *Context) bool
+	// SlowThreshold promotes the access log to WARN if latency exceeds it, 0 means disabled.
+	SlowThreshold time.Duration
+}
+
+type AccessLogOption func(*AccessLogConfig)
+
+func WithAccessFields(fields ...string) AccessLogOption {
+	return func(cfg *AccessLogConfig) {
+		if len(fields) > 0 {
+			cfg.Fields = fields
+		}
+	}
+}
+
+func WithAccessSkipPaths(paths ...string) AccessLogOption {
+	return func(cfg *AccessLogConfig) {
+		cfg.SkipPaths = paths
+	}
+}
+
+func WithAccessSkipper(skipper func(echo.Context, *Context) bool) AccessLogOption {
+	return func(cfg *AccessLogConfig) {
+		cfg.Skipper = skipper
+	}
+}
+
+func WithAccessSlowThreshold(threshold time.Duration) AccessLogOption {
+	return func(cfg *AccessLogConfig) {
+		if threshold > 0 {
+			cfg.SlowThreshold = threshold
+		}
+	}
+}
+
+// WithAccessLog makes the engine print an access log for each request.
+func WithAccessLog(opts ...AccessLogOption) Option {
+	return func(e *Engine) {
+		cfg := &AccessLogConfig{
+			Fields:    DefaultAccessFields,
+			SkipPaths: DefaultAccessSkipPaths,
+		}
+		for _, opt := range opts {
+			opt(cfg)
+		}
+
+		e.accessLog = cfg
+	}
+}
+
+func (cfg *AccessLogConfig) print(c echo.Context, ec *Context) {
+	if cfg.skip(c, ec) {
+		return
+	}
+
+	latency := time.Since(ec.GetStartTime())
+	fields := make([]zap.Field, 0, len(cfg.Fields)+1)
+	for _, field := range cfg.Fields {
+		switch field {
+		case AccessFieldMethod:
+			fields = append(fields, zap.String(field, c.Request().Method))
+		case AccessFieldPath:
+			fields = append(fields, zap.String(field, c.Request().URL.Path))
+		case AccessFieldRoute:
+			fields = append(fields, zap.String(field, c.Path()))
+		case AccessFieldHandler:
+			fields = append(fields, zap.String(field, ec.GetHandlerName()))
+		case AccessFieldCode:
+			fields = append(fields, zap.Int(field, ec.GetCode()))
+		case AccessFieldStatus:
+			fields = append(fields, zap.Int(field, c.Response().Status))
+		case AccessFieldLatency:
+			fields = append(fields, zap.Duration(field, latency))
+		case AccessFieldBytes:
+			fields = append(fields, zap.Int64(field, c.Response().Size))
+		case AccessFieldRequestID:
+			fields = append(fields, zap.String(field, GetRequestID(c)))
+		case AccessFieldClientIP:
+			fields = append(fields, zap.String(field, c.RealIP()))
+		case AccessFieldError:
+			if !ec.IsOK() && ec.GetError() != nil {
+				fields = append(fields, zap.String(field, ec.GetError().Error()))
+			}
+		}
+	}
+
+	level := zapcore.InfoLevel
+	if cfg.SlowThreshold > 0 && latency > cfg.SlowThreshold {
+		level = zapcore.WarnLevel
+		fields = append(fields, zap.Bool("slow", true))
+	}
+
+	CtxPrintKV(ec, level, "access", fields...)
+}
+
+func (cfg *AccessLogConfig) skip(c echo.Context, ec *Context) bool {
+	if cfg.Skipper != nil && cfg.Skipper(c, ec) {
+		return true
+	}
+
+	path := c.Request().URL.Path
+	for _, p := range cfg.SkipPaths {
+		if strings.HasSuffix(p, "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(p, "*")) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package echotool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEngine_WithAccessLog(t *testing.T) {
+	logs := observeLogs(t)
+
+	e := NewEngine(WithAccessLog(WithAccessSlowThreshold(time.Millisecond*10)), WithRecovery())
+	e.Use(AddNotice("tenant", "echotool"))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	PerformRequest(e, req, func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOK, "peter")
+	})
+
+	entries := logs.FilterMessage("access").AllUntimed()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+
+	fields := entries[0].ContextMap()
+	assert.Equal(t, http.MethodGet, fields[AccessFieldMethod])
+	assert.Equal(t, "/users", fields[AccessFieldPath])
+	assert.Equal(t, int64(CodeOK), fields[AccessFieldCode])
+	assert.Equal(t, int64(http.StatusOK), fields[AccessFieldStatus])
+	assert.Equal(t, "echotool", fields["tenant"])
+	assert.NotZero(t, fields[AccessFieldBytes])
+
+	PerformRequest(e, httptest.NewRequest(http.MethodGet, "/users", nil), func(c echo.Context, ec *Context) {
+		time.Sleep(time.Millisecond * 20)
+		panic("boom")
+	})
+
+	entries = logs.FilterMessage("access").AllUntimed()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, int64(http.StatusInternalServerError), entries[1].ContextMap()[AccessFieldStatus])
+	assert.Equal(t, "panic: boom", entries[1].ContextMap()[AccessFieldError])
+}
+
+func TestEngine_WithAccessLogSkip(t *testing.T) {
+	logs := observeLogs(t)
+
+	e := NewEngine(WithAccessLog())
+	for _, path := range []string{"/metrics", "/health/ready"} {
+		PerformRequest(e, httptest.NewRequest(http.MethodGet, path, nil), func(c echo.Context, ec *Context) {})
+	}
+
+	assert.Zero(t, logs.FilterMessage("access").Len())
+}
+
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	origin := logger
+	SetLogger(zap.New(core).Sugar())
+	t.Cleanup(func() {
+		SetLogger(origin)
+	})
+	return logs
+}
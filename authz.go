@@ -0,0 +1,384 @@
+package echotool
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/json"
+	"github.com/songzhaoliang/echotool/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	KeySubject = "authz_subject"
+
+	// PermissionAll grants all permissions.
+	PermissionAll = "*"
+)
+
+var (
+	ErrSubjectMissing     = errors.New("subject is missing")
+	ErrRoleNotMet         = errors.New("role is not met")
+	ErrPermissionNotMet   = errors.New("permission is not met")
+	ErrAttributeNotMet    = errors.New("attribute is not met")
+	ErrAttributeNotFound  = errors.New("attribute field is not found")
+	ErrAttributeUnchecked = errors.New("attribute rules are not checked")
+)
+
+// Policy is the authorization policy of a handler.
+type Policy struct {
+	// Roles are met if the subject has any of them.
+	Roles []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	// Permissions are met if the subject has all of them by its roles or scopes.
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// Attributes are checked against the bound request.
+	Attributes []AttributeRule `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+}
+
+// AttributeRule is met if the field of the bound request equals the attribute of the subject.
+type AttributeRule struct {
+	// Field is the path of the request field, e.g. "TenantID" or "Owner.ID".
+	Field     string `json:"field" yaml:"field"`
+	Attribute string `json:"attribute" yaml:"attribute"`
+}
+
+// Subject is the authenticated caller.
+type Subject struct {
+	ID         string
+	Roles      []string
+	Scopes     []string
+	Attributes map[string]string
+}
+
+// SubjectFunc returns the subject of the request, nil means the request is not authenticated.
+type SubjectFunc func(echo.Context, *Context) *Subject
+
+// JWTSubject returns the subject by the claims of JWTAuth, whose roles are claim "roles",
+// scopes are claim "scope" separated by spaces, and attributes are the other string claims.
+func JWTSubject(c echo.Context, ec *Context) *Subject {
+	claims := GetJWTClaims(c)
+	if claims == nil {
+		return nil
+	}
+	return newJWTSubject(claims)
+}
+
+func newJWTSubject(claims jwt.MapClaims) *Subject {
+	s := &Subject{
+		Attributes: make(map[string]string),
+	}
+	for k, v := range claims {
+		switch k {
+		case "roles":
+			switch roles := v.(type) {
+			case string:
+				s.Roles = strings.Fields(roles)
+			case []interface{}:
+				for _, role := range roles {
+					s.Roles = append(s.Roles, fmt.Sprint(role))
+				}
+			}
+		case "scope":
+			if scope, ok := v.(string); ok {
+				s.Scopes = strings.Fields(scope)
+			}
+		default:
+			if str, ok := v.(string); ok {
+				s.Attributes[k] = str
+			}
+		}
+	}
+	s.ID = s.Attributes["sub"]
+	return s
+}
+
+// PolicyEntry is the effective policy of a registered handler, Policy is nil if there is none.
+type PolicyEntry struct {
+	Handler string  `json:"handler"`
+	Policy  *Policy `json:"policy"`
+}
+
+// Authorizer checks the policies of handlers with the permissions of roles.
+type Authorizer struct {
+	roles   map[string]map[string]struct{}
+	subject SubjectFunc
+
+	lock    sync.RWMutex
+	entries []*PolicyEntry
+}
+
+type AuthorizerOption func(*Authorizer)
+
+func WithSubjectFunc(f SubjectFunc) AuthorizerOption {
+	return func(a *Authorizer) {
+		if f != nil {
+			a.subject = f
+		}
+	}
+}
+
+// NewAuthorizer returns an authorizer with the permissions of roles, see WithAuthorizer.
+func NewAuthorizer(roles map[string][]string, opts ...AuthorizerOption) *Authorizer {
+	a := &Authorizer{
+		roles:   make(map[string]map[string]struct{}, len(roles)),
+		subject: JWTSubject,
+	}
+	for role, permissions := range roles {
+		a.roles[role] = make(map[string]struct{}, len(permissions))
+		for _, permission := range permissions {
+			a.roles[role][permission] = struct{}{}
+		}
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// NewAuthorizerFromFile returns an authorizer with roles loaded from a json or yaml file, e.g.
+//
+//	roles:
+//	  admin: ["user:read", "user:write"]
+//	  viewer: ["user:read"]
+func NewAuthorizerFromFile(path string, opts ...AuthorizerOption) (*Authorizer, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg struct {
+		Roles map[string][]string `json:"roles" yaml:"roles"`
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &cfg)
+	default:
+		err = json.Unmarshal(b, &cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAuthorizer(cfg.Roles, opts...), nil
+}
+
+// WithAuthorizer makes the engine check the policy set by WithPolicy for each handler built by it,
+// after the middlewares, and record the policy for Policies.
+func WithAuthorizer(a *Authorizer) Option {
+	return func(e *Engine) {
+		e.authorizer = a
+	}
+}
+
+// WithPolicy sets the policy of the handlers built by the engine, it is usually used with Group, e.g.
+//
+//	r.POST("/users", e.Group(WithPolicy(&Policy{Permissions: []string{"user:write"}})).EchoHandler(CreateUser))
+func WithPolicy(p *Policy) Option {
+	return func(e *Engine) {
+		e.policy = p
+	}
+}
+
+// WithManualAuthorize allows the handlers which are not built by Handle or AddHandle to have a policy
+// with attribute rules, they must call Authorize before doing anything for the request.
+// Building such a handler panics without it, since the rules are only checked by the typed handlers.
+func WithManualAuthorize() Option {
+	return func(e *Engine) {
+		e.manualAuthz = true
+	}
+}
+
+// Policies returns the effective policies of all registered handlers.
+func (a *Authorizer) Policies() []*PolicyEntry {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	entries := make([]*PolicyEntry, len(a.entries))
+	copy(entries, a.entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Handler < entries[j].Handler
+	})
+	return entries
+}
+
+func (a *Authorizer) Register(r *echo.Echo, prefixes ...string) {
+	a.register(r.Group(util.GetPrefix(prefixes...)))
+}
+
+func (a *Authorizer) RouterRegister(g *echo.Group, prefixes ...string) {
+	a.register(g.Group(util.GetPrefix(prefixes...)))
+}
+
+func (a *Authorizer) register(g *echo.Group) {
+	g.GET("/authz/policies", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, a.Policies())
+	})
+}
+
+func (a *Authorizer) record(handlerName string, p *Policy) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.entries = append(a.entries, &PolicyEntry{
+		Handler: handlerName,
+		Policy:  p,
+	})
+}
+
+// handler checks the roles and permissions of p. If p has attribute rules, the response is
+// turned into CodeForbidden unless the handler checks them by Authorize.
+func (a *Authorizer) handler(p *Policy) HandlerFunc {
+	return func(c echo.Context, ec *Context) {
+		s := a.subject(c, ec)
+		if s == nil {
+			ec.Abort(CodeUnauthorized, ErrSubjectMissing)
+			return
+		}
+		c.Set(KeySubject, s)
+
+		if err := a.check(s, p); err != nil {
+			ec.Abort(CodeForbidden, err)
+			return
+		}
+
+		if len(p.Attributes) == 0 {
+			return
+		}
+		ec.Next()
+		if ec.IsOK() && !ec.authorized {
+			ec.Abort(CodeForbidden, ErrAttributeUnchecked)
+		}
+	}
+}
+
+func (a *Authorizer) check(s *Subject, p *Policy) error {
+	if len(p.Roles) > 0 && !hasAny(s.Roles, p.Roles) {
+		return fmt.Errorf("%w: requires any of %v", ErrRoleNotMet, p.Roles)
+	}
+
+	for _, permission := range p.Permissions {
+		if !a.permitted(s, permission) {
+			return fmt.Errorf("%w: requires %s", ErrPermissionNotMet, permission)
+		}
+	}
+	return nil
+}
+
+func (a *Authorizer) permitted(s *Subject, permission string) bool {
+	for _, scope := range s.Scopes {
+		if scope == permission || scope == PermissionAll {
+			return true
+		}
+	}
+	for _, role := range s.Roles {
+		permissions := a.roles[role]
+		if _, exists := permissions[permission]; exists {
+			return true
+		}
+		if _, exists := permissions[PermissionAll]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// GetSubject returns the subject checked by the authorizer.
+func GetSubject(c echo.Context) *Subject {
+	if s, ok := c.Get(KeySubject).(*Subject); ok {
+		return s
+	}
+	return nil
+}
+
+// Authorize checks the attribute rules of the policy against req, which is the bound request.
+// It is called by TypedHandler after binding, and must be called likewise by the handlers built
+// with WithManualAuthorize, otherwise their successful responses are turned into CodeForbidden
+// and Stream fails with ErrAttributeUnchecked. It fails with ErrContextDetached on a derived Context.
+func (ec *Context) Authorize(req interface{}) error {
+	if !ec.hasAttributeRules() {
+		return nil
+	}
+	if ec.echoContext == nil {
+		return AcquireEchotoolError(CodeInternalErr, ErrContextDetached)
+	}
+
+	s := GetSubject(ec.echoContext)
+	if s == nil {
+		return AcquireEchotoolError(CodeUnauthorized, ErrSubjectMissing)
+	}
+
+	for _, rule := range ec.engine.policy.Attributes {
+		value, err := fieldValue(req, rule.Field)
+		if err != nil {
+			return AcquireEchotoolError(CodeForbidden, err)
+		}
+		if attr, exists := s.Attributes[rule.Attribute]; !exists || attr != value {
+			return AcquireEchotoolError(CodeForbidden, fmt.Errorf("%w: %s must be %s", ErrAttributeNotMet, rule.Field, rule.Attribute))
+		}
+	}
+	ec.authorized = true
+	return nil
+}
+
+// MustAuthorize aborts with CodeForbidden if req does not meet the attribute rules of the policy.
+func (ec *Context) MustAuthorize(req interface{}) {
+	if err := ec.Authorize(req); err != nil {
+		panic(err)
+	}
+}
+
+func (ec *Context) hasAttributeRules() bool {
+	return ec.engine != nil && ec.engine.authorizer != nil && ec.engine.policy != nil &&
+		len(ec.engine.policy.Attributes) > 0
+}
+
+// isAuthorized reports whether the attribute rules of the policy, if any, have been met.
+func (ec *Context) isAuthorized() bool {
+	return ec.authorized || !ec.hasAttributeRules()
+}
+
+func fieldValue(obj interface{}, path string) (string, error) {
+	rv := reflect.ValueOf(obj)
+	for _, name := range strings.Split(path, ".") {
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return "", fmt.Errorf("%w: %s", ErrAttributeNotFound, path)
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return "", fmt.Errorf("%w: %s", ErrAttributeNotFound, path)
+		}
+		// the unexported fields can not be read.
+		if rv = rv.FieldByName(name); !rv.IsValid() || !rv.CanInterface() {
+			return "", fmt.Errorf("%w: %s", ErrAttributeNotFound, path)
+		}
+	}
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", nil
+		}
+		rv = rv.Elem()
+	}
+	return fmt.Sprint(rv.Interface()), nil
+}
+
+func hasAny(have, want []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package echotool
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type UpdateOrderReq struct {
+	TenantID string `header:"X-Tenant"`
+}
+
+type UpdateOrderResp struct{}
+
+func UpdateOrder(ec *Context, req *UpdateOrderReq) (*UpdateOrderResp, error) {
+	return &UpdateOrderResp{}, nil
+}
+
+func headerSubject(c echo.Context, ec *Context) *Subject {
+	roles := c.Request().Header.Get("X-Roles")
+	if roles == "" {
+		return nil
+	}
+	return &Subject{
+		Roles:      strings.Split(roles, ","),
+		Attributes: map[string]string{"tenant": c.Request().Header.Get("X-Subject-Tenant")},
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	a, err := NewAuthorizerFromFile("testdata/authz/roles.yaml", WithSubjectFunc(headerSubject))
+	assert.Nil(t, err)
+
+	e := NewEngine(WithAuthorizer(a))
+	handler := Handle(e.Group(WithPolicy(&Policy{
+		Permissions: []string{"order:write"},
+		Attributes:  []AttributeRule{{Field: "TenantID", Attribute: "tenant"}},
+	})), UpdateOrder)
+
+	perform := func(roles, subjectTenant, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/orders/1", nil)
+		req.Header.Set("X-Roles", roles)
+		req.Header.Set("X-Subject-Tenant", subjectTenant)
+		req.Header.Set("X-Tenant", tenant)
+		rec := httptest.NewRecorder()
+		_ = handler(echo.New().NewContext(req, rec))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, perform("editor", "t1", "t1").Code)
+	assert.Equal(t, http.StatusOK, perform("viewer,admin", "t1", "t1").Code)
+
+	rec := perform("viewer", "t1", "t1")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "requires order:write")
+
+	rec = perform("editor", "t1", "t2")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrAttributeNotMet.Error())
+
+	rec = perform("", "", "t1")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrSubjectMissing.Error())
+}
+
+func TestAuthorizer_Roles(t *testing.T) {
+	a := NewAuthorizer(nil, WithSubjectFunc(headerSubject))
+	e := NewEngine(WithAuthorizer(a)).Group(WithPolicy(&Policy{Roles: []string{"ops", "admin"}}))
+
+	perform := func(roles string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Roles", roles)
+		return PerformRequest(e, req, func(c echo.Context, ec *Context) {
+			ec.Finish(CodeOKZero, nil)
+		}).Code
+	}
+
+	assert.Equal(t, http.StatusOK, perform("dev,ops"))
+	assert.Equal(t, http.StatusForbidden, perform("dev"))
+}
+
+func TestAuthorizer_Untyped(t *testing.T) {
+	a := NewAuthorizer(nil, WithSubjectFunc(headerSubject))
+	e := NewEngine(WithAuthorizer(a)).Group(WithPolicy(&Policy{
+		Attributes: []AttributeRule{{Field: "TenantID", Attribute: "tenant"}},
+	}))
+
+	// the handler which is not typed can not have attribute rules unless it opts in.
+	assert.Panics(t, func() {
+		e.EchoHandler(routeListUsers)
+	})
+	assert.NotPanics(t, func() {
+		Handle(e, UpdateOrder)
+	})
+
+	e = e.Group(WithManualAuthorize())
+
+	perform := func(handler HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/orders/1", nil)
+		req.Header.Set("X-Roles", "editor")
+		req.Header.Set("X-Subject-Tenant", "t1")
+		req.Header.Set("X-Tenant", "t2")
+		return PerformRequest(e, req, handler)
+	}
+
+	// the handler never checks the attribute rules.
+	rec := perform(func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrAttributeUnchecked.Error())
+
+	rec = perform(func(c echo.Context, ec *Context) {
+		req := &UpdateOrderReq{}
+		MustBind(c, req, BHeader)
+		ec.MustAuthorize(req)
+		ec.Finish(CodeOKZero, nil)
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrAttributeNotMet.Error())
+
+	rec = perform(func(c echo.Context, ec *Context) {
+		ec.MustAuthorize(&UpdateOrderReq{TenantID: "t1"})
+		ec.Finish(CodeOKZero, nil)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// the stream is refused before anything is written.
+	rec = perform(func(c echo.Context, ec *Context) {
+		err := ec.Stream(func(send SendFunc) error {
+			return send("order", "t2")
+		})
+		assert.ErrorIs(t, err, ErrAttributeUnchecked)
+		abortWithError(ec, err, CodeInternalErr)
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "event: order")
+
+	rec = perform(func(c echo.Context, ec *Context) {
+		dc, cancel := ec.WithCancel()
+		defer cancel()
+		assert.ErrorIs(t, dc.Authorize(&UpdateOrderReq{TenantID: "t1"}), ErrContextDetached)
+		ec.MustAuthorize(&UpdateOrderReq{TenantID: "t1"})
+		ec.Finish(CodeOKZero, nil)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestFieldValue(t *testing.T) {
+	type owner struct {
+		ID     string
+		secret string
+	}
+	req := &struct {
+		Owner *owner
+		count int
+	}{Owner: &owner{ID: "u1", secret: "s"}}
+
+	v, err := fieldValue(req, "Owner.ID")
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", v)
+
+	for _, path := range []string{"Owner.secret", "count", "Owner.Name", "Owner.ID.Len"} {
+		_, err = fieldValue(req, path)
+		assert.ErrorIs(t, err, ErrAttributeNotFound, path)
+	}
+}
+
+func TestAuthorizer_Policies(t *testing.T) {
+	a := NewAuthorizer(nil)
+	e := NewEngine(WithAuthorizer(a))
+	policy := &Policy{Permissions: []string{"order:write"}}
+
+	Handle(e.Group(WithPolicy(policy)), UpdateOrder)
+	e.EchoHandler(PrintRequest())
+
+	r := echo.New()
+	a.Register(r)
+
+	req := httptest.NewRequest(http.MethodGet, "/authz/policies", nil)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var entries []*PolicyEntry
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	assert.Equal(t, []*PolicyEntry{
+		{Handler: "UpdateOrder", Policy: policy},
+		{Handler: "func1", Policy: nil},
+	}, entries)
+}
+
+func TestJWTSubject(t *testing.T) {
+	s := newJWTSubject(jwt.MapClaims{
+		"sub":    "alice",
+		"tenant": "t1",
+		"roles":  []interface{}{"editor"},
+		"scope":  "order:read order:write",
+		"exp":    float64(1),
+	})
+
+	assert.Equal(t, "alice", s.ID)
+	assert.Equal(t, []string{"editor"}, s.Roles)
+	assert.Equal(t, []string{"order:read", "order:write"}, s.Scopes)
+	assert.Equal(t, map[string]string{"sub": "alice", "tenant": "t1"}, s.Attributes)
+}
@@ -1,6 +1,8 @@
 package echotool
 
 import (
+	"errors"
+
 	"github.com/labstack/echo/v4"
 	"github.com/songzhaoliang/echotool/binder"
 	"github.com/songzhaoliang/echotool/validator"
@@ -42,7 +44,7 @@ func MustFormBindQuery(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // FormBindBody needs tag "form" in fields of v.
 func FormBindBody(c echo.Context, v interface{}) error {
-	return binder.FormPostBinder.Bind(c, v)
+	return bindBody(c, binder.FormPostBinder, v)
 }
 
 func MustFormBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -53,7 +55,7 @@ func MustFormBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // FormBindQueryBody needs tag "form" in fields of v.
 func FormBindQueryBody(c echo.Context, v interface{}) error {
-	return binder.FormBinder.Bind(c, v)
+	return bindBody(c, binder.FormBinder, v)
 }
 
 func MustFormBindQueryBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -64,7 +66,7 @@ func MustFormBindQueryBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // FormBindMultipart needs tag "form" in fields of v.
 func FormBindMultipart(c echo.Context, v interface{}) error {
-	return binder.FormMultipartBinder.Bind(c, v)
+	return bindBody(c, binder.FormMultipartBinder, v)
 }
 
 func MustFormBindMultipart(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -75,7 +77,7 @@ func MustFormBindMultipart(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // JSONBindBody needs tag "json" in fields of v.
 func JSONBindBody(c echo.Context, v interface{}) error {
-	return binder.JSONBodyBinder.Bind(c, v)
+	return bindBody(c, binder.JSONBodyBinder, v)
 }
 
 func MustJSONBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -86,7 +88,7 @@ func MustJSONBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // XMLBindBody needs tag "xml" in fields of v.
 func XMLBindBody(c echo.Context, v interface{}) error {
-	return binder.XMLBodyBinder.Bind(c, v)
+	return bindBody(c, binder.XMLBodyBinder, v)
 }
 
 func MustXMLBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -97,7 +99,7 @@ func MustXMLBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // ProtobufBindBody needs tag "protobuf" in fields of v.
 func ProtobufBindBody(c echo.Context, v interface{}) error {
-	return binder.ProtobufBodyBinder.Bind(c, v)
+	return bindBody(c, binder.ProtobufBodyBinder, v)
 }
 
 func MustProtobufBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -108,7 +110,7 @@ func MustProtobufBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // MsgpackBindBody needs tag "msgpack" in fields of v.
 func MsgpackBindBody(c echo.Context, v interface{}) error {
-	return binder.MsgpackBodyBinder.Bind(c, v)
+	return bindBody(c, binder.MsgpackBodyBinder, v)
 }
 
 func MustMsgpackBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -119,7 +121,7 @@ func MustMsgpackBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
 
 // YAMLBindBody needs tag "yaml" in fields of v.
 func YAMLBindBody(c echo.Context, v interface{}) error {
-	return binder.YAMLBodyBinder.Bind(c, v)
+	return bindBody(c, binder.YAMLBodyBinder, v)
 }
 
 func MustYAMLBindBody(c echo.Context, v interface{}, cbs ...CallbackFunc) {
@@ -178,6 +180,37 @@ const (
 	BCookie
 )
 
+var flagNames = []struct {
+	flag int
+	name string
+}{
+	{BValidator, "validator"},
+	{BHeader, "header"},
+	{BParam, "param"},
+	{BFormQuery, "form_query"},
+	{BFormBody, "form_body"},
+	{BFormQueryBody, "form_query_body"},
+	{BFormMultipart, "form_multipart"},
+	{BJSONBody, "json"},
+	{BXMLBody, "xml"},
+	{BProtobufBody, "protobuf"},
+	{BMsgpackBody, "msgpack"},
+	{BYAMLBody, "yaml"},
+	{BEnv, "env"},
+	{BCookie, "cookie"},
+}
+
+// BindFlagNames returns the names of the flags in flag, e.g. ["header", "json"] for BHeader|BJSONBody.
+func BindFlagNames(flag int) []string {
+	var names []string
+	for _, item := range flagNames {
+		if flag&item.flag != 0 {
+			names = append(names, item.name)
+		}
+	}
+	return names
+}
+
 var funcs = map[int]func(echo.Context, interface{}) error{
 	BHeader:        BindHeader,
 	BParam:         BindParam,
@@ -237,6 +270,26 @@ func Bind(c echo.Context, v interface{}, flag int) (err error) {
 	return
 }
 
+// bindBody binds the request body by b, and leaves the body buffered by BufferBody readable again.
+func bindBody(c echo.Context, b binder.Binder, v interface{}) error {
+	err := b.Bind(c, v)
+	rewindBody(c)
+	return bodyError(err)
+}
+
+// bodyError gives the errors of reading the request body their own codes.
+func bodyError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, binder.ErrBodyTooLarge):
+		return AcquireEchotoolError(CodeRequestEntityTooLarge, err)
+	case errors.Is(err, binder.ErrUnsupportedEncoding):
+		return AcquireEchotoolError(CodeBadRequest, err)
+	}
+	return err
+}
+
 func MustBind(c echo.Context, v interface{}, flag int, cbs ...CallbackFunc) {
 	MustDoCallback(func() (interface{}, error) {
 		return nil, Bind(c, v, flag)
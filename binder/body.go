@@ -0,0 +1,169 @@
+package binder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// KeyBodyLimit is the key of echo.Context to set the body limit of the request, see MaxBodySize.
+	KeyBodyLimit = "echotool_body_limit"
+	// KeyRawBody is the key of echo.Context to set the buffered request body,
+	// which is read again by each body binder.
+	KeyRawBody = "echotool_raw_body"
+
+	keyBodyPrepared = "echotool_body_prepared"
+)
+
+var (
+	// MaxBodySize is the global limit of the decompressed request body, 0 means no limit.
+	// It does not apply to multipart forms, whose files are stored on disk beyond MultipartMemoryMax,
+	// so uploads are only limited by KeyBodyLimit.
+	MaxBodySize int64 = 1 << 25
+	// MultipartMemoryMax is the max memory used to parse multipart forms, the rest is stored on disk.
+	MultipartMemoryMax int64 = 1 << 25
+)
+
+var (
+	ErrBodyTooLarge        = errors.New("request body too large")
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+// PrepareBody makes the request body decoded by header Content-Encoding (gzip and deflate)
+// and limited by the body limit, which is applied to the decompressed size.
+// Reading more than the limit fails with ErrBodyTooLarge.
+// It is called by all body binders and only works once for a request,
+// unless the body is buffered by KeyRawBody, which is rewound every time.
+func PrepareBody(c echo.Context) error {
+	if raw, ok := c.Get(KeyRawBody).([]byte); ok {
+		c.Request().Body = io.NopCloser(bytes.NewReader(raw))
+		return nil
+	}
+
+	if prepared, _ := c.Get(keyBodyPrepared).(bool); prepared {
+		return nil
+	}
+	c.Set(keyBodyPrepared, true)
+
+	req := c.Request()
+	if req.Body == nil {
+		return nil
+	}
+
+	limit := BodyLimit(c)
+	encoding := strings.ToLower(strings.TrimSpace(req.Header.Get(echo.HeaderContentEncoding)))
+	if encoding == "" || encoding == "identity" {
+		if limit > 0 && req.ContentLength > limit {
+			return ErrBodyTooLarge
+		}
+		if limit > 0 {
+			req.Body = &limitedBody{reader: req.Body, closer: req.Body, remaining: limit}
+		}
+		return nil
+	}
+
+	reader, err := decodeBody(req.Body, encoding)
+	if err != nil {
+		return err
+	}
+	if limit > 0 {
+		reader = &limitedBody{reader: reader, remaining: limit}
+	}
+
+	req.Body = &decodedBody{reader: reader, closer: req.Body}
+	req.Header.Del(echo.HeaderContentEncoding)
+	req.ContentLength = -1
+	return nil
+}
+
+// BodyLimit returns the body limit of the request set by KeyBodyLimit, or MaxBodySize
+// unless the request is a multipart form.
+func BodyLimit(c echo.Context) int64 {
+	if limit, ok := c.Get(KeyBodyLimit).(int64); ok {
+		return limit
+	}
+	if isMultipart(c.Request()) {
+		return 0
+	}
+	return MaxBodySize
+}
+
+func isMultipart(req *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	return mediaType == echo.MIMEMultipartForm
+}
+
+func decodeBody(body io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(body)
+	case "deflate":
+		// deflate should be zlib format, but some clients send the raw one.
+		br := bufio.NewReader(body)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedEncoding, encoding)
+}
+
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+type limitedBody struct {
+	reader    io.Reader
+	closer    io.Closer
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+
+	// read one more byte to know whether the limit is exceeded.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.reader.Read(p)
+	if b.remaining -= int64(n); b.remaining < 0 {
+		return n + int(b.remaining), ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	if b.closer != nil {
+		return b.closer.Close()
+	}
+	return nil
+}
+
+type decodedBody struct {
+	reader io.Reader
+	closer io.Closer
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+func (b *decodedBody) Close() error {
+	if closer, ok := b.reader.(io.Closer); ok {
+		closer.Close()
+	}
+	return b.closer.Close()
+}
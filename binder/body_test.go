@@ -0,0 +1,95 @@
+package binder
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	var (
+		buffer bytes.Buffer
+		w      io.WriteCloser
+	)
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buffer)
+	case "zlib":
+		w = zlib.NewWriter(&buffer)
+	default:
+		w, _ = flate.NewWriter(&buffer, flate.DefaultCompression)
+	}
+	_, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buffer.Bytes()
+}
+
+func newBodyContext(body []byte, encoding string) echo.Context {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestPrepareBody_Decompress(t *testing.T) {
+	data := []byte(`{"id":1,"name":"peter"}`)
+	for encoding, header := range map[string]string{"gzip": "gzip", "zlib": "deflate", "flate": "deflate"} {
+		c := newBodyContext(compress(t, encoding, data), header)
+
+		u := &User{}
+		assert.NoError(t, JSONBodyBinder.Bind(c, u), encoding)
+		assert.Equal(t, "peter", u.Name, encoding)
+		assert.Empty(t, c.Request().Header.Get("Content-Encoding"))
+	}
+}
+
+func TestPrepareBody_Limit(t *testing.T) {
+	data := []byte(`{"id":1,"name":"` + strings.Repeat("a", 200) + `"}`)
+
+	c := newBodyContext(data, "")
+	c.Set(KeyBodyLimit, int64(100))
+	assert.ErrorIs(t, JSONBodyBinder.Bind(c, &User{}), ErrBodyTooLarge)
+
+	// the limit applies to the decompressed size.
+	c = newBodyContext(compress(t, "gzip", data), "gzip")
+	c.Set(KeyBodyLimit, int64(100))
+	assert.Less(t, c.Request().ContentLength, int64(100))
+	assert.ErrorIs(t, JSONBodyBinder.Bind(c, &User{}), ErrBodyTooLarge)
+
+	c = newBodyContext(data, "")
+	c.Set(KeyBodyLimit, int64(len(data)))
+	assert.NoError(t, JSONBodyBinder.Bind(c, &User{}))
+}
+
+func TestPrepareBody_ZipBomb(t *testing.T) {
+	bomb := compress(t, "gzip", make([]byte, 64<<20))
+	c := newBodyContext(bomb, "gzip")
+
+	b, err := io.ReadAll(readBody(t, c))
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+	assert.Equal(t, MaxBodySize, int64(len(b)))
+}
+
+func TestPrepareBody_UnsupportedEncoding(t *testing.T) {
+	c := newBodyContext([]byte(`{}`), "br")
+	assert.ErrorIs(t, JSONBodyBinder.Bind(c, &User{}), ErrUnsupportedEncoding)
+}
+
+func readBody(t *testing.T, c echo.Context) io.Reader {
+	assert.NoError(t, PrepareBody(c))
+	// it only works once.
+	assert.NoError(t, PrepareBody(c))
+	return c.Request().Body
+}
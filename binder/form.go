@@ -12,10 +12,14 @@ type formBinder struct {
 var _ Binder = (*formBinder)(nil)
 
 func (formBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	if err := c.Request().ParseForm(); err != nil {
 		return err
 	}
 
-	c.Request().ParseMultipartForm(memoryMax)
+	c.Request().ParseMultipartForm(MultipartMemoryMax)
 	return Bind(obj, c.Request().Form, TagForm, false)
 }
@@ -12,7 +12,11 @@ type formMultipartBinder struct {
 var _ Binder = (*formMultipartBinder)(nil)
 
 func (formMultipartBinder) Bind(c echo.Context, obj interface{}) error {
-	if err := c.Request().ParseMultipartForm(memoryMax); err != nil {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
+	if err := c.Request().ParseMultipartForm(MultipartMemoryMax); err != nil {
 		return err
 	}
 
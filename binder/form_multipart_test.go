@@ -31,3 +31,32 @@ func TestFormMultipartBinder(t *testing.T) {
 	assert.Equal(t, 1, u.ID)
 	assert.Equal(t, "peter", u.Name)
 }
+
+func TestFormMultipartBinder_Limit(t *testing.T) {
+	origin := MaxBodySize
+	MaxBodySize = 16
+	defer func() {
+		MaxBodySize = origin
+	}()
+
+	newContext := func() echo.Context {
+		body := &bytes.Buffer{}
+		mv := multipart.NewWriter(body)
+		mv.WriteField("id", "1")
+		mv.WriteField("name", "peter")
+		mv.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", mv.FormDataContentType())
+		return echo.New().NewContext(req, httptest.NewRecorder())
+	}
+
+	// MaxBodySize does not apply to multipart forms.
+	u := &User{}
+	assert.NoError(t, FormMultipartBinder.Bind(newContext(), u))
+	assert.Equal(t, "peter", u.Name)
+
+	c := newContext()
+	c.Set(KeyBodyLimit, int64(16))
+	assert.ErrorIs(t, FormMultipartBinder.Bind(c, &User{}), ErrBodyTooLarge)
+}
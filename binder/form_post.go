@@ -12,6 +12,10 @@ type formPostBinder struct {
 var _ Binder = (*formPostBinder)(nil)
 
 func (formPostBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	if err := c.Request().ParseForm(); err != nil {
 		return err
 	}
@@ -9,8 +9,6 @@ import (
 )
 
 const (
-	memoryMax = 1 << 25
-
 	TagHeader   = "header"
 	TagParam    = "param"
 	TagForm     = "form"
@@ -18,6 +18,10 @@ type jsonBodyBinder struct {
 var _ Binder = (*jsonBodyBinder)(nil)
 
 func (jsonBodyBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	decoder := json.NewDecoder(c.Request().Body)
 	if EnableDecoderUseNumber {
 		decoder.UseNumber()
@@ -13,5 +13,9 @@ type msgpackBodyBinder struct {
 var _ Binder = (*msgpackBodyBinder)(nil)
 
 func (msgpackBodyBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	return codec.NewDecoder(c.Request().Body, &codec.MsgpackHandle{}).Decode(&obj)
 }
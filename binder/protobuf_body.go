@@ -15,6 +15,10 @@ type protobufBodyBinder struct {
 var _ Binder = (*protobufBodyBinder)(nil)
 
 func (protobufBodyBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	bs, err := ioutil.ReadAll(c.Request().Body)
 	if err != nil {
 		return err
@@ -14,5 +14,9 @@ type xmlBodyBinder struct {
 var _ Binder = (*xmlBodyBinder)(nil)
 
 func (xmlBodyBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	return xml.NewDecoder(c.Request().Body).Decode(obj)
 }
@@ -13,5 +13,9 @@ type yamlBodyBinder struct {
 var _ Binder = (*yamlBodyBinder)(nil)
 
 func (yamlBodyBinder) Bind(c echo.Context, obj interface{}) error {
+	if err := PrepareBody(c); err != nil {
+		return err
+	}
+
 	return yaml.NewDecoder(c.Request().Body).Decode(obj)
 }
@@ -0,0 +1,53 @@
+package echotool
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/binder"
+)
+
+// BufferBody returns a handler which reads the request body into a pooled buffer once, so that
+// all body binders, PrintRequest and the user code read the same bytes by GetRawBody or
+// Request().Body, which is rewound after each body binder.
+// The body is decompressed and limited as binder.PrepareBody does, so BodyLimit should be used before it.
+// The buffer is released with Context, so the bytes must not be used after the handler returns.
+func BufferBody() HandlerFunc {
+	return func(c echo.Context, ec *Context) {
+		if ec.body != nil {
+			return
+		}
+
+		if err := binder.PrepareBody(c); err != nil {
+			abortWithError(ec, bodyError(err), CodeBadRequest)
+			return
+		}
+
+		ec.body = AcquireBuffer()
+		if req := c.Request(); req.Body != nil {
+			_, err := ec.body.ReadFrom(req.Body)
+			req.Body.Close()
+			if err != nil {
+				abortWithError(ec, bodyError(err), CodeBadRequest)
+				return
+			}
+		}
+
+		c.Set(binder.KeyRawBody, ec.body.Bytes())
+		rewindBody(c)
+	}
+}
+
+// GetRawBody returns the request body buffered by BufferBody.
+func GetRawBody(c echo.Context) ([]byte, bool) {
+	raw, ok := c.Get(binder.KeyRawBody).([]byte)
+	return raw, ok
+}
+
+// rewindBody makes the buffered body readable from the beginning.
+func rewindBody(c echo.Context) {
+	if raw, ok := GetRawBody(c); ok {
+		c.Request().Body = io.NopCloser(bytes.NewReader(raw))
+	}
+}
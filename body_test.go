@@ -0,0 +1,99 @@
+package echotool
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type SignedReq struct {
+	Name string `json:"name"`
+}
+
+func TestBufferBody(t *testing.T) {
+	body := `{"name":"peter"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	var released *Context
+	rec := PerformRequest(NewEngine(), req, BufferBody(), PrintRequest(), func(c echo.Context, ec *Context) {
+		first, second := &SignedReq{}, &SignedReq{}
+		MustJSONBindBody(c, first)
+		MustBind(c, second, BJSONBody)
+		assert.Equal(t, "peter", first.Name)
+		assert.Equal(t, "peter", second.Name)
+
+		raw, ok := GetRawBody(c)
+		assert.True(t, ok)
+		assert.Equal(t, body, string(raw))
+
+		b, err := io.ReadAll(c.Request().Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, string(b))
+
+		released = ec
+		ec.Finish(CodeOKZero, nil)
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, released.body)
+}
+
+func TestBufferBody_Released(t *testing.T) {
+	resetRoutes(t)
+
+	var (
+		raw  []byte
+		ok   bool
+		body []byte
+	)
+	r := echo.New()
+	r.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			// the buffer has been released after the handler.
+			raw, ok = GetRawBody(c)
+			body, _ = io.ReadAll(c.Request().Body)
+			return err
+		}
+	})
+	NewEngine().Add(r, http.MethodPost, "/", BufferBody(), func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"peter"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, ok)
+	assert.Nil(t, raw)
+	assert.Empty(t, body)
+}
+
+func TestBufferBody_Decompress(t *testing.T) {
+	var buffer bytes.Buffer
+	w := gzip.NewWriter(&buffer)
+	_, _ = w.Write([]byte(`{"name":"peter"}`))
+	_ = w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &buffer)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderContentEncoding, "gzip")
+
+	rec := PerformRequest(NewEngine(), req, BufferBody(), func(c echo.Context, ec *Context) {
+		raw, _ := GetRawBody(c)
+		assert.Equal(t, `{"name":"peter"}`, string(raw))
+		ec.Finish(CodeOKZero, nil)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"peter"}`))
+	rec = PerformRequest(NewEngine(), req, BodyLimit(4), BufferBody())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
@@ -0,0 +1,342 @@
+package echotool
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/songzhaoliang/echotool/metric"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+var _ fmt.Stringer = (*BreakerState)(nil)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("unknown breaker state: %d", s)
+}
+
+const (
+	MBreakerState       = "breaker_state"
+	MBreakerTransitions = "breaker_transitions_total"
+)
+
+var (
+	ErrBreakerOpen = errors.New("breaker is open")
+)
+
+// BreakerConfig is the config of a circuit breaker.
+type BreakerConfig struct {
+	// Window is the interval in which the closed breaker counts requests, the counts are cleared after each window.
+	Window time.Duration
+	// MinRequests is the minimal number of requests in a window before the breaker can trip.
+	MinRequests int
+	// FailureRatio trips the breaker once the ratio of failures in a window reaches it.
+	FailureRatio float64
+	// CoolDown is the duration of the open state, after which the breaker becomes half-open.
+	CoolDown time.Duration
+	// HalfOpenRequests is the number of trial requests let through by the half-open breaker,
+	// it is closed if all of them succeed, and opened again once one of them fails.
+	HalfOpenRequests int
+	// IsFailure reports whether err is a failure of the downstream, all errors are by default.
+	IsFailure func(error) bool
+}
+
+type BreakerOption func(*BreakerConfig)
+
+func WithBreakerWindow(window time.Duration) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		if window > 0 {
+			cfg.Window = window
+		}
+	}
+}
+
+func WithBreakerMinRequests(n int) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		if n > 0 {
+			cfg.MinRequests = n
+		}
+	}
+}
+
+func WithBreakerFailureRatio(ratio float64) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		if ratio > 0 && ratio <= 1 {
+			cfg.FailureRatio = ratio
+		}
+	}
+}
+
+func WithBreakerCoolDown(coolDown time.Duration) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		if coolDown > 0 {
+			cfg.CoolDown = coolDown
+		}
+	}
+}
+
+func WithBreakerHalfOpenRequests(n int) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		if n > 0 {
+			cfg.HalfOpenRequests = n
+		}
+	}
+}
+
+func WithBreakerIsFailure(isFailure func(error) bool) BreakerOption {
+	return func(cfg *BreakerConfig) {
+		if isFailure != nil {
+			cfg.IsFailure = isFailure
+		}
+	}
+}
+
+// Breaker is a circuit breaker for calls of a downstream.
+// It fails fast with CodeServiceUnavailable and ErrBreakerOpen when it is open.
+// The state changes are logged and recorded by metrics MBreakerState and MBreakerTransitions,
+// which are defined by DefineBreakerMetrics.
+type Breaker struct {
+	name string
+	cfg  *BreakerConfig
+
+	lock       sync.Mutex
+	state      BreakerState
+	generation uint64
+	expiry     time.Time
+	requests   int
+	failures   int
+	successes  int
+}
+
+var breakers sync.Map
+
+// GetBreaker returns the breaker named name, it is created by opts if it does not exist.
+func GetBreaker(name string, opts ...BreakerOption) *Breaker {
+	if b, exists := breakers.Load(name); exists {
+		return b.(*Breaker)
+	}
+
+	b, _ := breakers.LoadOrStore(name, NewBreaker(name, opts...))
+	return b.(*Breaker)
+}
+
+// NewBreaker returns a closed breaker, which is not shared by name, see GetBreaker.
+func NewBreaker(name string, opts ...BreakerOption) *Breaker {
+	cfg := &BreakerConfig{
+		Window:           time.Second * 10,
+		MinRequests:      20,
+		FailureRatio:     0.5,
+		CoolDown:         time.Second * 5,
+		HalfOpenRequests: 1,
+		IsFailure: func(error) bool {
+			return true
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	b := &Breaker{
+		name: name,
+		cfg:  cfg,
+	}
+	b.toClosed(time.Now())
+	return b
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() BreakerState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refresh(time.Now())
+	return b.state
+}
+
+// Do calls run if the breaker is not open.
+// A panic of run, e.g. raised by Must functions, is counted as a failure and panics again.
+func (b *Breaker) Do(run RunFunc) (interface{}, error) {
+	generation, err := b.before()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(error)
+			if !ok {
+				perr = fmt.Errorf("panic: %v", r)
+			}
+			b.after(generation, perr)
+			panic(r)
+		}
+	}()
+
+	result, err := run()
+	b.after(generation, err)
+	return result, err
+}
+
+// Wrap returns a RunFunc which calls run by Do, it can be passed to MustDo and Retry.
+func (b *Breaker) Wrap(run RunFunc) RunFunc {
+	return func() (interface{}, error) {
+		return b.Do(run)
+	}
+}
+
+func (b *Breaker) before() (uint64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refresh(time.Now())
+	switch b.state {
+	case BreakerOpen:
+		return 0, AcquireEchotoolError(CodeServiceUnavailable, ErrBreakerOpen)
+	case BreakerHalfOpen:
+		if b.requests >= b.cfg.HalfOpenRequests {
+			return 0, AcquireEchotoolError(CodeServiceUnavailable, ErrBreakerOpen)
+		}
+	}
+
+	b.requests++
+	return b.generation, nil
+}
+
+func (b *Breaker) after(generation uint64, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	b.refresh(now)
+	// the call was let through in a previous state.
+	if generation != b.generation {
+		return
+	}
+
+	if err != nil && b.cfg.IsFailure(err) {
+		b.failures++
+		switch b.state {
+		case BreakerClosed:
+			if b.requests >= b.cfg.MinRequests && float64(b.failures)/float64(b.requests) >= b.cfg.FailureRatio {
+				b.setState(BreakerOpen, now)
+			}
+		case BreakerHalfOpen:
+			b.setState(BreakerOpen, now)
+		}
+		return
+	}
+
+	b.successes++
+	if b.state == BreakerHalfOpen && b.successes >= b.cfg.HalfOpenRequests {
+		b.setState(BreakerClosed, now)
+	}
+}
+
+// refresh moves to the next window of the closed state, or to the half-open state after cool-down.
+func (b *Breaker) refresh(now time.Time) {
+	if now.Before(b.expiry) {
+		return
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		b.toClosed(now)
+	case BreakerOpen:
+		b.setState(BreakerHalfOpen, now)
+	}
+}
+
+func (b *Breaker) setState(state BreakerState, now time.Time) {
+	prev := b.state
+	switch state {
+	case BreakerClosed:
+		b.toClosed(now)
+	case BreakerOpen:
+		b.newGeneration(now.Add(b.cfg.CoolDown))
+	case BreakerHalfOpen:
+		b.newGeneration(time.Time{})
+	}
+	b.state = state
+
+	Warn("breaker %s changes from %s to %s", b.name, prev, state)
+	_ = metric.EmitGauge(MBreakerState, float64(state), newBreakerLabels(b.name))
+	_ = metric.EmitCounter(MBreakerTransitions, 1, newTransitionLabels(b.name, state.String()))
+}
+
+func (b *Breaker) toClosed(now time.Time) {
+	b.newGeneration(now.Add(b.cfg.Window))
+}
+
+func (b *Breaker) newGeneration(expiry time.Time) {
+	b.generation++
+	b.expiry = expiry
+	b.requests = 0
+	b.failures = 0
+	b.successes = 0
+}
+
+// DefineBreakerMetrics defines the metrics recorded by Breaker in c.
+func DefineBreakerMetrics(c *metric.MetricClient) error {
+	if err := c.DefineGauge(MBreakerState, newBreakerLabels("")); err != nil {
+		return err
+	}
+	return c.DefineCounter(MBreakerTransitions, newTransitionLabels("", ""))
+}
+
+type breakerLabels struct {
+	Name string
+}
+
+var _ metric.LabelsParser = (*breakerLabels)(nil)
+
+func newBreakerLabels(name string) *breakerLabels {
+	return &breakerLabels{
+		Name: name,
+	}
+}
+
+func (ls *breakerLabels) ParseToLabels() map[string]string {
+	return map[string]string{
+		"name": ls.Name,
+	}
+}
+
+type transitionLabels struct {
+	Name  string
+	State string
+}
+
+var _ metric.LabelsParser = (*transitionLabels)(nil)
+
+func newTransitionLabels(name, state string) *transitionLabels {
+	return &transitionLabels{
+		Name:  name,
+		State: state,
+	}
+}
+
+func (ls *transitionLabels) ParseToLabels() map[string]string {
+	return map[string]string{
+		"name":  ls.Name,
+		"state": ls.State,
+	}
+}
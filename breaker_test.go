@@ -0,0 +1,89 @@
+package echotool
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errDownstream = errors.New("downstream error")
+
+func TestBreaker(t *testing.T) {
+	b := NewBreaker("test",
+		WithBreakerMinRequests(4),
+		WithBreakerFailureRatio(0.5),
+		WithBreakerCoolDown(time.Millisecond*20),
+		WithBreakerHalfOpenRequests(2))
+
+	succeed := func() (interface{}, error) { return "ok", nil }
+	fail := func() (interface{}, error) { return nil, errDownstream }
+
+	_, _ = b.Do(succeed)
+	_, _ = b.Do(succeed)
+	_, _ = b.Do(fail)
+	assert.Equal(t, BreakerClosed, b.State())
+	_, _ = b.Do(fail)
+	assert.Equal(t, BreakerOpen, b.State())
+
+	_, err := b.Do(succeed)
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+	assert.Equal(t, CodeServiceUnavailable, ErrorCode(err, CodeDownstreamErr))
+
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	_, _ = b.Do(fail)
+	assert.Equal(t, BreakerOpen, b.State())
+
+	time.Sleep(time.Millisecond * 30)
+	result, err := b.Do(succeed)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	_, _ = b.Do(succeed)
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestBreaker_MustDo(t *testing.T) {
+	b := NewBreaker("must_do", WithBreakerMinRequests(1), WithBreakerCoolDown(time.Hour))
+	_, _ = b.Do(func() (interface{}, error) { return nil, errDownstream })
+
+	defer func() {
+		r := recover()
+		e, ok := r.(*EchotoolError)
+		assert.True(t, ok)
+		assert.Equal(t, CodeServiceUnavailable, e.GetCode())
+	}()
+
+	MustDo(b.Wrap(func() (interface{}, error) {
+		t.Fatal("open breaker calls downstream")
+		return nil, nil
+	}))
+}
+
+func TestBreaker_Panic(t *testing.T) {
+	b := NewBreaker("panic", WithBreakerMinRequests(1), WithBreakerCoolDown(time.Millisecond*20))
+	_, _ = b.Do(func() (interface{}, error) { return nil, errDownstream })
+	time.Sleep(time.Millisecond * 30)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+
+	assert.Panics(t, func() {
+		_, _ = b.Do(func() (interface{}, error) {
+			panic("boom")
+		})
+	})
+	// the panicking trial opens the breaker again instead of keeping its slot.
+	assert.Equal(t, BreakerOpen, b.State())
+
+	time.Sleep(time.Millisecond * 30)
+	result, err := b.Do(func() (interface{}, error) { return "ok", nil })
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", result)
+	assert.Equal(t, BreakerClosed, b.State())
+}
+
+func TestGetBreaker(t *testing.T) {
+	assert.Same(t, GetBreaker("shared"), GetBreaker("shared"))
+	assert.NotSame(t, GetBreaker("shared"), GetBreaker("other"))
+}
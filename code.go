@@ -2,6 +2,7 @@ package echotool
 
 import (
 	"net/http"
+	"strconv"
 )
 
 const (
@@ -19,12 +20,16 @@ const (
 	CodeTemporaryRedirect = 30700
 	CodePermanentRedirect = 30800
 
-	CodeBadRequest      = 40000
-	CodeUnauthorized    = 40100
-	CodeForbidden       = 40300
-	CodeNotFound        = 40400
-	CodeTooManyRequests = 42900
-	CodeValidateErr     = 45000
+	CodeBadRequest            = 40000
+	CodeUnauthorized          = 40100
+	CodeForbidden             = 40300
+	CodeNotFound              = 40400
+	CodeConflict              = 40900
+	CodePreconditionFailed    = 41200
+	CodeRequestEntityTooLarge = 41300
+	CodeUnprocessableEntity   = 42200
+	CodeTooManyRequests       = 42900
+	CodeValidateErr           = 45000
 
 	CodeInternalErr        = 50000
 	CodeServiceUnavailable = 50300
@@ -58,12 +63,16 @@ var codeMsg = map[int]string{
 	CodeTemporaryRedirect: "temporary redirect",
 	CodePermanentRedirect: "permanent redirect",
 
-	CodeBadRequest:      "bad request",
-	CodeUnauthorized:    "unauthorized",
-	CodeForbidden:       "forbidden",
-	CodeNotFound:        "not found",
-	CodeTooManyRequests: "too many requests",
-	CodeValidateErr:     "validate error",
+	CodeBadRequest:            "bad request",
+	CodeUnauthorized:          "unauthorized",
+	CodeForbidden:             "forbidden",
+	CodeNotFound:              "not found",
+	CodeConflict:              "conflict",
+	CodePreconditionFailed:    "precondition failed",
+	CodeRequestEntityTooLarge: "request entity too large",
+	CodeUnprocessableEntity:   "unprocessable entity",
+	CodeTooManyRequests:       "too many requests",
+	CodeValidateErr:           "validate error",
 
 	CodeInternalErr:        "internal error",
 	CodeServiceUnavailable: "service unavailable",
@@ -104,12 +113,16 @@ var httpStatus = map[int]int{
 	CodeTemporaryRedirect: http.StatusTemporaryRedirect,
 	CodePermanentRedirect: http.StatusPermanentRedirect,
 
-	CodeBadRequest:      http.StatusBadRequest,
-	CodeUnauthorized:    http.StatusUnauthorized,
-	CodeForbidden:       http.StatusForbidden,
-	CodeNotFound:        http.StatusNotFound,
-	CodeTooManyRequests: http.StatusTooManyRequests,
-	CodeValidateErr:     http.StatusBadRequest,
+	CodeBadRequest:            http.StatusBadRequest,
+	CodeUnauthorized:          http.StatusUnauthorized,
+	CodeForbidden:             http.StatusForbidden,
+	CodeNotFound:              http.StatusNotFound,
+	CodeConflict:              http.StatusConflict,
+	CodePreconditionFailed:    http.StatusPreconditionFailed,
+	CodeRequestEntityTooLarge: http.StatusRequestEntityTooLarge,
+	CodeUnprocessableEntity:   http.StatusUnprocessableEntity,
+	CodeTooManyRequests:       http.StatusTooManyRequests,
+	CodeValidateErr:           http.StatusBadRequest,
 
 	CodeInternalErr:        http.StatusInternalServerError,
 	CodeServiceUnavailable: http.StatusInternalServerError,
@@ -139,6 +152,18 @@ func HTTPStatus(code int) int {
 	return UnknownStatus
 }
 
+// CodeClass returns the class of code by its first digit, e.g. "2xxxx" for CodeOK.
+// CodeOKZero is in class "0", and the codes out of range are in class "other".
+func CodeClass(code int) string {
+	switch {
+	case code == CodeOKZero:
+		return "0"
+	case code >= 10000 && code < 100000:
+		return strconv.Itoa(code/10000) + "xxxx"
+	}
+	return "other"
+}
+
 // RegisterCode will not cover code and status which exists.
 func RegisterCode(code int, msg string, status int) bool {
 	if _, exists := codeMsg[code]; exists {
@@ -7,10 +7,10 @@ import (
 )
 
 type CommonResponse struct {
-	RequestID string      `json:"request_id,omitempty"`
-	Code      int         `json:"code"`
-	Message   string      `json:"message"`
-	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"request_id,omitempty" xml:"request_id,omitempty" yaml:"request_id,omitempty"`
+	Code      int         `json:"code" xml:"code" yaml:"code"`
+	Message   string      `json:"message" xml:"message" yaml:"message"`
+	Data      interface{} `json:"data,omitempty" xml:"data,omitempty" yaml:"data,omitempty"`
 }
 
 func RespOK(id string, code int, data interface{}) *CommonResponse {
@@ -34,17 +34,22 @@ func RespError(id string, code int, err error) *CommonResponse {
 	return resp
 }
 
+// FinishWithCodeData renders the response in the media type negotiated by header Accept.
+// CodeNotModified is sent without body.
 func FinishWithCodeData(c echo.Context, code int, data interface{}) {
 	status := HTTPStatus(code)
 	if status < http.StatusMultipleChoices || status > http.StatusPermanentRedirect {
-		c.JSON(status, RespOK(GetRequestID(c), code, data))
+		Render(c, status, RespOK(GetRequestID(c), code, data))
+	} else if status == http.StatusNotModified {
+		c.NoContent(status)
 	} else {
 		c.Redirect(status, data.(string))
 	}
 }
 
+// AbortWithCodeErr renders the response in the media type negotiated by header Accept.
 func AbortWithCodeErr(c echo.Context, code int, err error) {
-	c.JSON(HTTPStatus(code), RespError(GetRequestID(c), code, err))
+	Render(c, HTTPStatus(code), RespError(GetRequestID(c), code, err))
 }
 
 func GetCommonFinisher() HandlerFunc {
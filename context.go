@@ -1,16 +1,40 @@
 package echotool
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/labstack/echo/v4"
 	"github.com/popeyeio/handy"
+	"github.com/songzhaoliang/echotool/binder"
 )
 
+const (
+	// abortIndex is kept small so that Next never overflows int after AbortChain, even on 32-bit platforms.
+	// The handler chains must be shorter than it.
+	abortIndex = math.MaxInt8 / 2
+)
+
+var (
+	// ErrContextDetached is returned by the methods which need the request,
+	// when they are called on a Context derived by WithCancel and the like.
+	ErrContextDetached = errors.New("context is detached from the request")
+)
+
+type contextKey struct{}
+
 type Context struct {
 	engine      *Engine
+	echoContext echo.Context
+	ctx         context.Context
 	handlers    HandlerFuncsChain
+	index       int
 	handlerName string
 
 	ok   bool
@@ -20,29 +44,102 @@ type Context struct {
 
 	namedValue   string
 	customValues map[string]string
+	debugLog     bool
+	authorized   bool
+
+	valuesLock sync.RWMutex
+	values     map[string]interface{}
 
 	startTime time.Time
+	streamed  bool
+
+	etag         string
+	lastModified time.Time
+
+	body *bytes.Buffer
 }
 
 var _ context.Context = (*Context)(nil)
 var _ fmt.Stringer = (*Context)(nil)
 
+// FromContext returns the *Context carried by ctx, or nil if there is none.
+// It also finds the *Context behind contexts derived by the standard library.
+func FromContext(ctx context.Context) *Context {
+	if ctx == nil {
+		return nil
+	}
+	if ec, ok := ctx.(*Context); ok {
+		return ec
+	}
+	ec, _ := ctx.Value(contextKey{}).(*Context)
+	return ec
+}
+
 func (ec *Context) Deadline() (deadline time.Time, ok bool) {
-	return
+	return ec.context().Deadline()
 }
 
 func (ec *Context) Done() <-chan struct{} {
-	return nil
+	return ec.context().Done()
 }
 
 func (ec *Context) Err() error {
-	return nil
+	return ec.context().Err()
 }
 
 func (ec *Context) Value(key interface{}) interface{} {
+	if key == (contextKey{}) {
+		return ec
+	}
+	return ec.context().Value(key)
+}
+
+// WithCancel returns a copy of ec which is canceled with the request or when cancel is called.
+func (ec *Context) WithCancel() (*Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ec.context())
+	return ec.derive(ctx), cancel
+}
+
+// WithDeadline returns a copy of ec whose deadline is no later than d.
+func (ec *Context) WithDeadline(d time.Time) (*Context, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(ec.context(), d)
+	return ec.derive(ctx), cancel
+}
+
+// WithTimeout returns WithDeadline(time.Now().Add(timeout)).
+func (ec *Context) WithTimeout(timeout time.Duration) (*Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ec.context(), timeout)
+	return ec.derive(ctx), cancel
+}
+
+// WithoutCancel returns a copy of ec which is not canceled with the request and has no deadline,
+// e.g. for the work which must be done after the client goes away. It keeps the values of ec.
+func (ec *Context) WithoutCancel() *Context {
+	return ec.derive(withoutCancel{ec.context()})
+}
+
+// withoutCancel is context.WithoutCancel before go1.21.
+type withoutCancel struct {
+	context.Context
+}
+
+func (withoutCancel) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (withoutCancel) Done() <-chan struct{} {
 	return nil
 }
 
+func (withoutCancel) Err() error {
+	return nil
+}
+
+// WithValue returns a copy of ec in which the value associated with key is val.
+func (ec *Context) WithValue(key, val interface{}) *Context {
+	return ec.derive(context.WithValue(ec.context(), key, val))
+}
+
 func (ec *Context) String() string {
 	if ec.ok {
 		return fmt.Sprintf("code:%d, data:%s", ec.code, handy.Stringify(ec.data))
@@ -50,6 +147,50 @@ func (ec *Context) String() string {
 	return fmt.Sprintf("code:%d, error:%v", ec.code, ec.err)
 }
 
+// Next runs the pending handlers in the chain, so it should only be used inside middlewares.
+// After Next returns, the middleware sees the final code, data and error of the downstream handlers.
+func (ec *Context) Next() {
+	ec.index++
+	for ec.index < len(ec.handlers) {
+		ec.call(ec.handlers[ec.index])
+
+		if !ec.IsOK() {
+			ec.AbortChain()
+		}
+		ec.index++
+	}
+}
+
+// AbortChain prevents the pending handlers from being called, but keeps code, data and error.
+// It is not necessary to call AbortChain after Abort.
+func (ec *Context) AbortChain() {
+	ec.index = abortIndex
+}
+
+func (ec *Context) IsChainAborted() bool {
+	return ec.index >= abortIndex
+}
+
+// call converts *EchotoolError panics from the handler into Abort,
+// so that the middlewares around it can still see the result.
+// So are the other panics if the engine is built with WithRecovery.
+func (ec *Context) call(handler HandlerFunc) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(*EchotoolError); ok {
+				ec.Abort(err.GetCode(), err.GetError())
+				ReleaseEchotoolError(err)
+			} else if ec.engine != nil && ec.engine.canRecover(r) {
+				ec.engine.recoverPanic(ec, r)
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	handler(ec.echoContext, ec)
+}
+
 func (ec *Context) Finish(code int, data interface{}) {
 	ec.ok = true
 	ec.code = code
@@ -96,13 +237,29 @@ func (ec *Context) GetCustomValue(key string) (value string, exists bool) {
 }
 
 func (ec *Context) SetCustomValue(key, value string) {
+	if ec.customValues == nil {
+		ec.customValues = make(map[string]string)
+	}
 	ec.customValues[key] = value
 }
 
+// GetCustomValues returns the custom values of ec, which can be written by the caller.
 func (ec *Context) GetCustomValues() map[string]string {
+	if ec.customValues == nil {
+		ec.customValues = make(map[string]string)
+	}
 	return ec.customValues
 }
 
+// SetDebugLog makes the logs of the request printed regardless of the level of logger.
+func (ec *Context) SetDebugLog(debug bool) {
+	ec.debugLog = debug
+}
+
+func (ec *Context) IsDebugLog() bool {
+	return ec.debugLog
+}
+
 func (ec *Context) GetHandlerName() string {
 	return ec.handlerName
 }
@@ -111,16 +268,60 @@ func (ec *Context) GetStartTime() time.Time {
 	return ec.startTime
 }
 
+// Clone returns a copy of the log identity and values of ec, which is still valid after the handler returns.
 func (ec *Context) Clone() *Context {
 	return &Context{
 		namedValue:   ec.namedValue,
-		customValues: ec.customValues,
+		customValues: ec.copyCustomValues(),
+		debugLog:     ec.debugLog,
+		values:       ec.copyValues(),
 	}
 }
 
+// context returns the context which ec is backed by.
+// A pooled Context follows the request of echo.Context, a derived one keeps its own.
+func (ec *Context) context() context.Context {
+	if ec.ctx != nil {
+		return ec.ctx
+	}
+	if ec.echoContext != nil {
+		if req := ec.echoContext.Request(); req != nil {
+			return req.Context()
+		}
+	}
+	return context.Background()
+}
+
+// derive returns a Context which is not pooled, so it is still valid after the handler returns.
+func (ec *Context) derive(ctx context.Context) *Context {
+	return &Context{
+		engine:       ec.engine,
+		ctx:          ctx,
+		handlerName:  ec.handlerName,
+		ok:           true,
+		namedValue:   ec.namedValue,
+		customValues: ec.copyCustomValues(),
+		debugLog:     ec.debugLog,
+		values:       ec.copyValues(),
+		startTime:    ec.startTime,
+	}
+}
+
+func (ec *Context) copyCustomValues() map[string]string {
+	customValues := make(map[string]string, len(ec.customValues))
+	for k, v := range ec.customValues {
+		customValues[k] = v
+	}
+	return customValues
+}
+
 func (ec *Context) reset() {
+	ec.releaseBody()
 	ec.engine = nil
-	ec.handlers = ec.handlers[:0]
+	ec.echoContext = nil
+	ec.ctx = nil
+	ec.handlers = nil // shared by all requests of the route
+	ec.index = -1
 	ec.handlerName = handy.StrEmpty
 	ec.ok = false
 	ec.code = 0
@@ -128,4 +329,29 @@ func (ec *Context) reset() {
 	ec.err = nil
 	ec.namedValue = handy.StrEmpty
 	ec.customValues = nil
+	ec.debugLog = false
+	ec.authorized = false
+	ec.valuesLock.Lock()
+	ec.values = nil
+	ec.valuesLock.Unlock()
+	ec.streamed = false
+	ec.etag = handy.StrEmpty
+	ec.lastModified = time.Time{}
+}
+
+// releaseBody releases the buffer of BufferBody, the echo middlewares running after the handler
+// see an empty body instead of the recycled bytes.
+func (ec *Context) releaseBody() {
+	if ec.body == nil {
+		return
+	}
+
+	if c := ec.echoContext; c != nil {
+		c.Set(binder.KeyRawBody, nil)
+		if req := c.Request(); req != nil {
+			req.Body = http.NoBody
+		}
+	}
+	ReleaseBuffer(ec.body)
+	ec.body = nil
 }
@@ -0,0 +1,98 @@
+package echotool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_RequestCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	var err error
+	PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		cancel()
+		<-ec.Done()
+		err = ec.Err()
+		ec.Finish(CodeOKZero, nil)
+	})
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestContext_WithTimeout(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	var child *Context
+	PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		ec.SetNamedValue("trace")
+		ec.SetCustomValue("k", "v")
+
+		var cancel context.CancelFunc
+		child, cancel = ec.WithTimeout(time.Millisecond)
+		defer cancel()
+
+		_, ok := child.Deadline()
+		assert.True(t, ok)
+		<-child.Done()
+		assert.Equal(t, context.DeadlineExceeded, child.Err())
+	})
+
+	// the derived context keeps its fields after the pooled one is released.
+	assert.Equal(t, "trace", child.GetNamedValue())
+	v, _ := child.GetCustomValue("k")
+	assert.Equal(t, "v", v)
+}
+
+func TestContext_GetCustomValues(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		// the map is writable even if no custom value is set.
+		ec.GetCustomValues()["k"] = "v"
+		v, ok := ec.GetCustomValue("k")
+		assert.True(t, ok)
+		assert.Equal(t, "v", v)
+	})
+}
+
+func TestContext_WithValue(t *testing.T) {
+	type key struct{}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		child := ec.WithValue(key{}, "value")
+		assert.Equal(t, "value", child.Value(key{}))
+		assert.Nil(t, ec.Value(key{}))
+
+		std, cancel := context.WithCancel(child)
+		defer cancel()
+		assert.Same(t, child, FromContext(std))
+	})
+}
+
+func TestContext_Release(t *testing.T) {
+	e := NewEngine()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ec := e.acquireContext(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), nil))
+	assert.Equal(t, context.Canceled, ec.Err())
+
+	e.releaseContext(ec)
+	assert.Nil(t, ec.Done())
+	assert.NoError(t, ec.Err())
+}
+
+func PerformRequest(e *Engine, req *http.Request, handlers ...HandlerFunc) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	_ = e.EchoHandler(handlers...)(c)
+	return rec
+}
@@ -0,0 +1,75 @@
+package echotool
+
+import (
+	"crypto/subtle"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderDebugLog = "X-Debug-Log"
+)
+
+// DebugLogTrustFunc reports whether the caller is trusted to raise the log level of its request.
+type DebugLogTrustFunc func(echo.Context, *Context) bool
+
+// TrustCIDRs trusts the callers whose real ip is in one of cidrs, a single ip is accepted as well.
+// The real ip relies on the IPExtractor of echo, which should not trust the forwarded headers blindly.
+func TrustCIDRs(cidrs ...string) (DebugLogTrustFunc, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+
+	return func(c echo.Context, ec *Context) bool {
+		ip := net.ParseIP(c.RealIP())
+		if ip == nil {
+			return false
+		}
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// TrustToken trusts the callers whose header key carries token.
+func TrustToken(key, token string) DebugLogTrustFunc {
+	return func(c echo.Context, ec *Context) bool {
+		v := c.Request().Header.Get(key)
+		return token != "" && subtle.ConstantTimeCompare([]byte(v), []byte(token)) == 1
+	}
+}
+
+// DebugLog returns a handler which prints all logs of the request regardless of the level of logger,
+// if header X-Debug-Log of the request is true and the caller is trusted.
+// It should be used before the handlers whose logs are wanted.
+func DebugLog(trusted DebugLogTrustFunc) HandlerFunc {
+	return func(c echo.Context, ec *Context) {
+		debug, err := strconv.ParseBool(c.Request().Header.Get(HeaderDebugLog))
+		if err != nil || !debug {
+			return
+		}
+
+		if trusted != nil && trusted(c, ec) {
+			ec.SetDebugLog(true)
+		}
+	}
+}
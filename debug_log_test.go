@@ -0,0 +1,83 @@
+package echotool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDebugLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	origin := logger
+	SetLogger(zap.New(core).Sugar())
+	t.Cleanup(func() {
+		SetLogger(origin)
+	})
+
+	trusted, err := TrustCIDRs("10.0.0.0/8", "192.0.2.1")
+	assert.Nil(t, err)
+
+	cases := []struct {
+		name   string
+		ip     string
+		header string
+		debug  bool
+	}{
+		{"trusted", "10.1.2.3", "true", true},
+		{"trusted single ip", "192.0.2.1", "1", true},
+		{"untrusted", "203.0.113.1", "true", false},
+		{"no header", "10.1.2.3", "", false},
+		{"false header", "10.1.2.3", "false", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(echo.HeaderXRealIP, c.ip)
+			if c.header != "" {
+				req.Header.Set(HeaderDebugLog, c.header)
+			}
+
+			logs.TakeAll()
+			PerformRequest(NewEngine(), req, DebugLog(trusted), func(c echo.Context, ec *Context) {
+				ec.SetCustomValue("user", "u1")
+				CtxDebug(ec, "debug")
+				CtxDebugKV(ec, "debug kv")
+				CtxInfo(ec, "info")
+				ec.Finish(CodeOK, nil)
+			})
+
+			entries := logs.TakeAll()
+			if c.debug {
+				assert.Len(t, entries, 3)
+				assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+				assert.Equal(t, "u1", entries[0].ContextMap()["user"])
+			} else {
+				assert.Len(t, entries, 1)
+				assert.Equal(t, "info", entries[0].Message)
+			}
+		})
+	}
+
+	_, err = TrustCIDRs("10.0.0.0/33")
+	assert.NotNil(t, err)
+}
+
+func TestTrustToken(t *testing.T) {
+	trusted := TrustToken("X-Debug-Token", "secret")
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Debug-Token", "secret")
+	assert.True(t, trusted(e.NewContext(req, nil), nil))
+
+	req.Header.Set("X-Debug-Token", "wrong")
+	assert.False(t, trusted(e.NewContext(req, nil), nil))
+
+	assert.False(t, TrustToken("X-Debug-Token", "")(e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), nil), nil))
+}
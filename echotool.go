@@ -1,24 +1,44 @@
 package echotool
 
 import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/songzhaoliang/echotool/health"
 	"github.com/songzhaoliang/echotool/metric"
 	"github.com/songzhaoliang/echotool/pprof"
 	"github.com/songzhaoliang/echotool/swagger"
+	"github.com/songzhaoliang/echotool/trace"
 )
 
+// HandlerFunc is used as both handler and middleware.
+// A middleware may simply return to let the next handler run,
+// or call Context.Next to wrap the downstream handlers like an onion.
 type HandlerFunc func(echo.Context, *Context)
 type HandlerFuncsChain []HandlerFunc
 
+// PanicReporter is called with the recovered value and the stack when a handler panics.
+type PanicReporter func(c echo.Context, ec *Context, r interface{}, stack []byte)
+
 type Engine struct {
-	middlewares HandlerFuncsChain
-	finisher    HandlerFunc
-	aborter     HandlerFunc
-	contextPool sync.Pool
+	middlewares    HandlerFuncsChain
+	finisher       HandlerFunc
+	aborter        HandlerFunc
+	recovery       bool
+	panicReporters []PanicReporter
+	accessLog      *AccessLogConfig
+	metricClient   *metric.MetricClient
+	etag           *etagConfig
+	authorizer     *Authorizer
+	policy         *Policy
+	manualAuthz    bool
+	tracer         *trace.Tracer
+	contextPool    sync.Pool
 }
 
 type Option func(*Engine)
@@ -39,6 +59,21 @@ func WithAborter(aborter HandlerFunc) Option {
 	}
 }
 
+// WithRecovery turns the panics which are not *EchotoolError into CodeInternalErr,
+// instead of re-raising them to echo. The stack is logged by CtxError and passed to reporters.
+func WithRecovery(reporters ...PanicReporter) Option {
+	return func(e *Engine) {
+		e.recovery = true
+		e.panicReporters = append(e.panicReporters, reporters...)
+	}
+}
+
+func WithMiddlewares(middlewares ...HandlerFunc) Option {
+	return func(e *Engine) {
+		e.Use(middlewares...)
+	}
+}
+
 func NewEngine(opts ...Option) *Engine {
 	e := &Engine{
 		finisher: GetCommonFinisher(),
@@ -52,6 +87,9 @@ func NewEngine(opts ...Option) *Engine {
 	return e
 }
 
+// NewDefaultEcho returns an echo instance with the common middlewares and debug endpoints.
+// The endpoints of loglevel and RegisterRoutes are not mounted since they have no authentication,
+// their RouterRegister functions should be used with an authenticated group.
 func NewDefaultEcho() *echo.Echo {
 	r := echo.New()
 	r.Use(middleware.Recover())
@@ -59,6 +97,7 @@ func NewDefaultEcho() *echo.Echo {
 	pprof.Register(r)
 	metric.Register(r)
 	swagger.Register(r)
+	health.Register(r)
 	return r
 }
 
@@ -69,45 +108,119 @@ func NewDefaultEngine(opts ...Option) *Engine {
 	return e
 }
 
+// Use adds middlewares for the handlers built by EchoHandler afterwards.
 func (e *Engine) Use(middlewares ...HandlerFunc) *Engine {
 	e.middlewares = append(e.middlewares, middlewares...)
 	return e
 }
 
+// Group returns a child engine which inherits the middlewares, finisher and aborter of e.
+// The child can add its own middlewares and override the finisher and aborter by opts,
+// which never affects e. Middlewares added to e after Group are not inherited.
+func (e *Engine) Group(opts ...Option) *Engine {
+	g := &Engine{
+		middlewares:    make(HandlerFuncsChain, len(e.middlewares)),
+		finisher:       e.finisher,
+		aborter:        e.aborter,
+		recovery:       e.recovery,
+		panicReporters: make([]PanicReporter, len(e.panicReporters)),
+		accessLog:      e.accessLog,
+		metricClient:   e.metricClient,
+		etag:           e.etag,
+		authorizer:     e.authorizer,
+		policy:         e.policy,
+		manualAuthz:    e.manualAuthz,
+		tracer:         e.tracer,
+	}
+	copy(g.middlewares, e.middlewares)
+	copy(g.panicReporters, e.panicReporters)
+
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// EchoHandler builds the handler chain and the handler name once, so middlewares must be
+// added by Use before EchoHandler is called. The route is not recorded for Routes, use Engine.Add instead.
 func (e *Engine) EchoHandler(handlers ...HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		if len(handlers) == 0 {
+	if len(handlers) == 0 {
+		return func(c echo.Context) error {
 			return nil
 		}
+	}
+
+	return e.echoHandler(&RouteInfo{Handler: GetHandlerName(handlers[0])}, handlers...)
+}
 
-		ec := e.acquireContext()
+func (e *Engine) echoHandler(ri *RouteInfo, handlers ...HandlerFunc) echo.HandlerFunc {
+	handlerName := ri.Handler
+	chain := make(HandlerFuncsChain, 0, len(e.middlewares)+len(handlers)+1)
+	chain = append(chain, e.middlewares...)
+	if e.authorizer != nil {
+		e.authorizer.record(handlerName, e.policy)
+		if e.policy != nil {
+			chain = append(chain, e.authorizer.handler(e.policy))
+			if len(e.policy.Attributes) > 0 && !ri.typed && !e.manualAuthz {
+				panic(fmt.Sprintf("%s has attribute rules in its policy, it must be built by Handle or AddHandle, "+
+					"or by an engine with WithManualAuthorize", handlerName))
+			}
+		}
+	}
+	ri.Middlewares = middlewareNames(chain)
+	chain = append(chain, handlers...)
+	if len(chain) >= abortIndex {
+		panic(fmt.Sprintf("too many handlers for %s: %d", handlerName, len(chain)))
+	}
+
+	return func(c echo.Context) error {
+		ec := e.acquireContext(c)
 		defer e.releaseContext(ec)
 
+		// the access log and metrics are recorded after the panic is recovered.
+		if e.accessLog != nil {
+			defer e.accessLog.print(c, ec)
+		}
+		if e.metricClient != nil {
+			defer e.finishMetrics(c, ec)
+		}
+		if e.tracer != nil {
+			span := e.startSpan(c, ec, handlerName)
+			defer e.endSpan(c, ec, span)
+		}
+
 		defer func() {
 			if r := recover(); r != nil {
 				if err, ok := r.(*EchotoolError); ok {
 					ec.Abort(err.GetCode(), err.GetError())
 					ReleaseEchotoolError(err)
 					e.aborter(c, ec)
+				} else if e.canRecover(r) {
+					e.recoverPanic(ec, r)
+					e.aborter(c, ec)
 				} else {
 					panic(r)
 				}
 			}
 		}()
 
-		ec.handlerName = GetHandlerName(handlers[0])
-		ec.handlers = append(ec.handlers, e.middlewares...)
-		ec.handlers = append(ec.handlers, handlers...)
+		ec.handlerName = handlerName
+		ec.handlers = chain
 
-		for _, handler := range ec.handlers {
-			handler(c, ec)
+		if e.metricClient != nil {
+			e.startMetrics(ec)
+		}
 
-			if !ec.IsOK() {
-				break
-			}
+		ec.Next()
+
+		if ec.IsStreamed() {
+			// the response has been written by Stream.
+			return nil
 		}
 
 		if ec.IsOK() {
+			e.conditional(c, ec)
 			e.finisher(c, ec)
 		} else {
 			e.aborter(c, ec)
@@ -117,7 +230,27 @@ func (e *Engine) EchoHandler(handlers ...HandlerFunc) echo.HandlerFunc {
 	}
 }
 
-func (e *Engine) acquireContext() (ec *Context) {
+func (e *Engine) canRecover(r interface{}) bool {
+	// http.ErrAbortHandler is used to abort the response on purpose.
+	return e.recovery && r != http.ErrAbortHandler
+}
+
+func (e *Engine) recoverPanic(ec *Context, r interface{}) {
+	stack := debug.Stack()
+	CtxError(ec, "panic recovered: %v\n%s", r, stack)
+
+	for _, report := range e.panicReporters {
+		report(ec.echoContext, ec, r, stack)
+	}
+
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("panic: %v", r)
+	}
+	ec.Abort(CodeInternalErr, err)
+}
+
+func (e *Engine) acquireContext(c echo.Context) (ec *Context) {
 	if v := e.contextPool.Get(); v != nil {
 		ec = v.(*Context)
 	} else {
@@ -125,8 +258,9 @@ func (e *Engine) acquireContext() (ec *Context) {
 	}
 
 	ec.engine = e
+	ec.echoContext = c
+	ec.index = -1
 	ec.ok = true
-	ec.customValues = make(map[string]string)
 	ec.startTime = time.Now()
 	return
 }
@@ -0,0 +1,185 @@
+package echotool
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_LinearMiddlewares(t *testing.T) {
+	var steps []string
+	e := NewEngine()
+	e.Use(func(c echo.Context, ec *Context) {
+		steps = append(steps, "m1")
+	}, func(c echo.Context, ec *Context) {
+		steps = append(steps, "m2")
+		ec.Abort(CodeForbidden, errors.New("forbidden"))
+	})
+
+	rec := PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		steps = append(steps, "handler")
+	})
+
+	assert.Equal(t, []string{"m1", "m2"}, steps)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestEngine_OnionMiddlewares(t *testing.T) {
+	var steps []string
+	e := NewEngine()
+	e.Use(func(c echo.Context, ec *Context) {
+		steps = append(steps, "before")
+		ec.Next()
+		steps = append(steps, "after")
+		assert.Equal(t, CodeCreated, ec.GetCode())
+		assert.Equal(t, "data", ec.GetData())
+	}, func(c echo.Context, ec *Context) {
+		steps = append(steps, "linear")
+	})
+
+	rec := PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		steps = append(steps, "handler")
+		ec.Finish(CodeCreated, "data")
+	})
+
+	assert.Equal(t, []string{"before", "linear", "handler", "after"}, steps)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestEngine_OnionSeesPanicAbort(t *testing.T) {
+	var code int
+	e := NewEngine()
+	e.Use(func(c echo.Context, ec *Context) {
+		ec.Next()
+		code = ec.GetCode()
+		assert.True(t, ec.IsChainAborted())
+	})
+
+	rec := PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		MustDo(func() (interface{}, error) {
+			return nil, errors.New("downstream")
+		})
+	}, func(c echo.Context, ec *Context) {
+		t.Fatal("should not be called")
+	})
+
+	assert.Equal(t, CodeDownstreamErr, code)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestEngine_AbortChain(t *testing.T) {
+	e := NewEngine()
+	e.Use(func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOK, "cached")
+		ec.AbortChain()
+	})
+
+	rec := PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		t.Fatal("should not be called")
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "cached")
+}
+
+func TestEngine_TooManyHandlers(t *testing.T) {
+	handlers := make([]HandlerFunc, abortIndex)
+	for i := range handlers {
+		handlers[i] = func(c echo.Context, ec *Context) {}
+	}
+
+	assert.Panics(t, func() {
+		NewEngine().EchoHandler(handlers...)
+	})
+	assert.NotPanics(t, func() {
+		NewEngine().EchoHandler(handlers[1:]...)
+	})
+}
+
+func TestEngine_Group(t *testing.T) {
+	var steps []string
+	middleware := func(name string) HandlerFunc {
+		return func(c echo.Context, ec *Context) {
+			steps = append(steps, name)
+		}
+	}
+
+	e := NewEngine(WithMiddlewares(middleware("root")))
+	admin := e.Group(WithMiddlewares(middleware("admin")), WithAborter(func(c echo.Context, ec *Context) {
+		c.String(HTTPStatus(ec.GetCode()), "admin aborter")
+	}))
+	super := admin.Group(WithMiddlewares(middleware("super")))
+
+	handler := func(c echo.Context, ec *Context) {
+		ec.Abort(CodeForbidden, nil)
+	}
+
+	rec := PerformRequest(super, httptest.NewRequest(http.MethodGet, "/", nil), handler)
+	assert.Equal(t, []string{"root", "admin", "super"}, steps)
+	assert.Equal(t, "admin aborter", rec.Body.String())
+
+	steps = nil
+	rec = PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), handler)
+	assert.Equal(t, []string{"root"}, steps)
+	assert.NotEqual(t, "admin aborter", rec.Body.String())
+}
+
+func BenchmarkEngine_EchoHandler(b *testing.B) {
+	nop := func(c echo.Context, ec *Context) {}
+	e := NewEngine(WithFinisher(nop), WithAborter(nop))
+	e.Use(nop, nop, nop)
+	h := e.EchoHandler(func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	})
+
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = h(c)
+	}
+}
+
+func TestEngine_WithRecovery(t *testing.T) {
+	var (
+		reported interface{}
+		stack    []byte
+		code     int
+	)
+	e := NewEngine(WithRecovery(func(c echo.Context, ec *Context, r interface{}, s []byte) {
+		reported, stack = r, s
+	}))
+	e.Use(func(c echo.Context, ec *Context) {
+		ec.Next()
+		code = ec.GetCode()
+	})
+
+	rec := PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		panic("boom")
+	})
+
+	assert.Equal(t, "boom", reported)
+	assert.Contains(t, string(stack), "TestEngine_WithRecovery")
+	assert.Equal(t, CodeInternalErr, code)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "panic: boom")
+}
+
+func TestEngine_WithoutRecovery(t *testing.T) {
+	assert.Panics(t, func() {
+		PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+			panic("boom")
+		})
+	})
+
+	assert.Panics(t, func() {
+		PerformRequest(NewEngine(WithRecovery()), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+			panic(http.ErrAbortHandler)
+		})
+	})
+}
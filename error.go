@@ -109,6 +109,10 @@ func (e EchotoolError) Error() string {
 	return fmt.Sprintf("%s - %+v", CodeMsg(e.code), e.err)
 }
 
+func (e EchotoolError) Unwrap() error {
+	return e.err
+}
+
 func (e *EchotoolError) reset() {
 	e.code = 0
 	e.err = nil
@@ -0,0 +1,189 @@
+package echotool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/json"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+var (
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+type etagConfig struct {
+	weak bool
+}
+
+// WithETag makes the engine compute an ETag for the successful GET and HEAD requests whose handlers
+// do not set one by Context.SetETag. The ETag is hashed over the code, the data encoded to json and
+// the negotiated media type, it is weak if weak is true.
+func WithETag(weak bool) Option {
+	return func(e *Engine) {
+		e.etag = &etagConfig{
+			weak: weak,
+		}
+	}
+}
+
+// FormatETag quotes value as an entity tag.
+func FormatETag(value string, weak bool) string {
+	etag := strconv.Quote(value)
+	if weak {
+		etag = "W/" + etag
+	}
+	return etag
+}
+
+// SetETag sets the ETag of the response, etag is quoted as a strong one if it is not an entity tag.
+// The successful GET and HEAD requests matching header If-None-Match are finished with CodeNotModified.
+func (ec *Context) SetETag(etag string) {
+	ec.etag = normalizeETag(etag)
+}
+
+func (ec *Context) GetETag() string {
+	return ec.etag
+}
+
+// SetLastModified sets header Last-Modified of the response.
+// The successful GET and HEAD requests not modified since header If-Modified-Since are finished
+// with CodeNotModified, unless there is header If-None-Match.
+func (ec *Context) SetLastModified(t time.Time) {
+	ec.lastModified = t
+}
+
+func (ec *Context) GetLastModified() time.Time {
+	return ec.lastModified
+}
+
+// CheckIfMatch checks header If-Match against etag, which is the current ETag of the resource to be
+// written. etag is empty if the resource does not exist. An *EchotoolError with CodePreconditionFailed
+// is returned if they do not match, so that the lost update is rejected.
+// It fails with CodeInternalErr and ErrContextDetached on a derived Context, which has no request.
+func (ec *Context) CheckIfMatch(etag string) error {
+	if ec.echoContext == nil {
+		return AcquireEchotoolError(CodeInternalErr, ErrContextDetached)
+	}
+
+	header := ec.echoContext.Request().Header.Get(HeaderIfMatch)
+	if header == "" {
+		return nil
+	}
+
+	if etag != "" {
+		etag = normalizeETag(etag)
+		for _, tag := range splitETags(header) {
+			// If-Match uses the strong comparison.
+			if tag == "*" || (tag == etag && !isWeakETag(tag)) {
+				return nil
+			}
+		}
+	}
+	return AcquireEchotoolError(CodePreconditionFailed, ErrPreconditionFailed)
+}
+
+// MustCheckIfMatch aborts with CodePreconditionFailed if header If-Match does not match etag.
+func (ec *Context) MustCheckIfMatch(etag string) {
+	if err := ec.CheckIfMatch(etag); err != nil {
+		panic(err)
+	}
+}
+
+// conditional sets the validators of the successful GET and HEAD requests,
+// and finishes with CodeNotModified if the request is fresh.
+func (e *Engine) conditional(c echo.Context, ec *Context) {
+	req := c.Request()
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return
+	}
+	if status := HTTPStatus(ec.GetCode()); status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return
+	}
+
+	header := c.Response().Header()
+	if ec.etag == "" && e.etag != nil {
+		// the computed ETag depends on the negotiated media type, so do 304 responses.
+		ec.etag = computeETag(c, ec, e.etag.weak)
+		varyAccept(header)
+	}
+
+	if ec.etag != "" {
+		header.Set(HeaderETag, ec.etag)
+	}
+	if !ec.lastModified.IsZero() {
+		header.Set(echo.HeaderLastModified, ec.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if isFresh(req, ec.etag, ec.lastModified) {
+		ec.Finish(CodeNotModified, nil)
+	}
+}
+
+func computeETag(c echo.Context, ec *Context, weak bool) string {
+	b, err := json.Marshal(ec.GetData())
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(strconv.Itoa(ec.GetCode()) + "\n"))
+	h.Write([]byte(Negotiate(c.Request().Header.Get(echo.HeaderAccept)) + "\n"))
+	h.Write(b)
+	return FormatETag(hex.EncodeToString(h.Sum(nil)[:16]), weak)
+}
+
+func isFresh(req *http.Request, etag string, lastModified time.Time) bool {
+	if header := req.Header.Get(HeaderIfNoneMatch); header != "" {
+		if etag == "" {
+			return false
+		}
+
+		// If-None-Match uses the weak comparison.
+		for _, tag := range splitETags(header) {
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := req.Header.Get(echo.HeaderIfModifiedSince); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+func splitETags(header string) []string {
+	tags := strings.Split(header, ",")
+	for i := range tags {
+		tags[i] = strings.TrimSpace(tags[i])
+	}
+	return tags
+}
+
+func normalizeETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return strconv.Quote(etag)
+}
+
+func isWeakETag(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
@@ -0,0 +1,110 @@
+package echotool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_WithETag(t *testing.T) {
+	e := NewEngine(WithETag(true))
+	getUser := func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, map[string]string{"name": "alice"})
+	}
+
+	rec := PerformRequest(e, httptest.NewRequest(http.MethodGet, "/users/1", nil), getUser)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get(HeaderETag)
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, etag)
+	assert.Equal(t, []string{echo.HeaderAccept}, rec.Header().Values(echo.HeaderVary))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(HeaderIfNoneMatch, `"other", `+etag)
+	rec = PerformRequest(e, req, getUser)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get(HeaderETag))
+	assert.Equal(t, []string{echo.HeaderAccept}, rec.Header().Values(echo.HeaderVary))
+
+	// the representation in another media type has another ETag.
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(HeaderIfNoneMatch, etag)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationXML)
+	rec = PerformRequest(e, req, getUser)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// writes are not conditional.
+	req = httptest.NewRequest(http.MethodPost, "/users/1", nil)
+	req.Header.Set(HeaderIfNoneMatch, etag)
+	rec = PerformRequest(e, req, getUser)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(HeaderETag))
+}
+
+func TestContext_SetLastModified(t *testing.T) {
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	getUser := func(c echo.Context, ec *Context) {
+		ec.SetETag("v1")
+		ec.SetLastModified(modified)
+		ec.Finish(CodeOKZero, nil)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(echo.HeaderIfModifiedSince, modified.Format(http.TimeFormat))
+	rec := PerformRequest(NewEngine(), req, getUser)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, `"v1"`, rec.Header().Get(HeaderETag))
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", rec.Header().Get(echo.HeaderLastModified))
+
+	// If-None-Match takes precedence over If-Modified-Since.
+	req.Header.Set(HeaderIfNoneMatch, `"v0"`)
+	rec = PerformRequest(NewEngine(), req, getUser)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(echo.HeaderIfModifiedSince, modified.Add(-time.Second).Format(http.TimeFormat))
+	rec = PerformRequest(NewEngine(), req, getUser)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestContext_MustCheckIfMatch(t *testing.T) {
+	updateUser := func(current string) HandlerFunc {
+		return func(c echo.Context, ec *Context) {
+			ec.MustCheckIfMatch(current)
+			ec.Finish(CodeOKZero, nil)
+		}
+	}
+	perform := func(ifMatch, current string) int {
+		req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+		if ifMatch != "" {
+			req.Header.Set(HeaderIfMatch, ifMatch)
+		}
+		return PerformRequest(NewEngine(), req, updateUser(current)).Code
+	}
+
+	assert.Equal(t, http.StatusOK, perform("", "v2"))
+	assert.Equal(t, http.StatusOK, perform(`"v2"`, "v2"))
+	assert.Equal(t, http.StatusOK, perform(`"v1", "v2"`, `"v2"`))
+	assert.Equal(t, http.StatusOK, perform("*", "v2"))
+	assert.Equal(t, http.StatusPreconditionFailed, perform(`"v1"`, "v2"))
+	assert.Equal(t, http.StatusPreconditionFailed, perform(`W/"v2"`, `W/"v2"`))
+	assert.Equal(t, http.StatusPreconditionFailed, perform("*", ""))
+}
+
+func TestContext_CheckIfMatch_Derived(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+	req.Header.Set(HeaderIfMatch, `"v2"`)
+	PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		dc, cancel := ec.WithCancel()
+		defer cancel()
+
+		err := dc.CheckIfMatch("v2")
+		assert.ErrorIs(t, err, ErrContextDetached)
+		assert.Equal(t, CodeInternalErr, ErrorCode(err, CodeOKZero))
+		ec.Finish(CodeOKZero, nil)
+	})
+}
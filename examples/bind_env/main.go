@@ -1,6 +1,8 @@
 package main
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/songzhaoliang/echotool"
 )
@@ -16,7 +18,7 @@ func main() {
 
 	e := echotool.NewEngine()
 
-	r.GET("/credentials", e.EchoHandler(GetCredential))
+	e.Add(r, http.MethodGet, "/credentials", GetCredential)
 
 	r.Start(":1323")
 }
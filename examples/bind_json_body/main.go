@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"github.com/songzhaoliang/echotool"
@@ -18,7 +19,7 @@ func main() {
 
 	e := echotool.NewEngine()
 
-	r.POST("/users", e.EchoHandler(CreateUser))
+	e.Add(r, http.MethodPost, "/users", CreateUser)
 
 	r.Start(":1323")
 }
@@ -31,7 +31,7 @@ func main() {
 
 	e := echotool.NewEngine()
 
-	r.POST("/users", e.EchoHandler(CreateUser))
+	e.Add(r, http.MethodPost, "/users", CreateUser)
 
 	r.Start(":1323")
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool"
+)
+
+func main() {
+	r := echo.New()
+	r.Use(echotool.SetRequestID(echotool.GetUUID))
+
+	e := echotool.NewDefaultEngine()
+	admin := e.Group(echotool.WithMiddlewares(CheckAdmin))
+
+	e.Add(r, http.MethodGet, "/users", ListUsers)
+	admin.Add(r, http.MethodDelete, "/users/:id", DeleteUser)
+
+	r.Start(":1323")
+}
+
+func CheckAdmin(c echo.Context, ec *echotool.Context) {
+	if token := echotool.MustHeaderString(c, "X-Token"); token != "AdminToken" {
+		ec.Abort(echotool.CodeForbidden, fmt.Errorf("not admin"))
+	}
+}
+
+func ListUsers(c echo.Context, ec *echotool.Context) {
+	ec.Finish(echotool.CodeOKZero, []string{"peter"})
+}
+
+func DeleteUser(c echo.Context, ec *echotool.Context) {
+	id := echotool.MustParamInt64(c, "id")
+
+	fmt.Printf("delete user %d\n", id)
+
+	ec.Finish(echotool.CodeOKZero, nil)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"net/http"
+
 	"github.com/labstack/echo/v4"
 	"github.com/songzhaoliang/echotool"
 	"go.uber.org/zap"
@@ -21,7 +23,7 @@ func main() {
 	e.Use(echotool.AddTraceID(echotool.GetRequestID))
 	e.Use(echotool.AddNotice("host", echotool.GetHostname()))
 
-	r.POST("/users", e.EchoHandler(CreateUser))
+	e.Add(r, http.MethodPost, "/users", CreateUser)
 
 	r.Start(":1323")
 }
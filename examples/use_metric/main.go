@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"github.com/songzhaoliang/echotool"
@@ -23,9 +24,10 @@ func main() {
 	r := echo.New()
 	metric.Register(r)
 
-	e := echotool.NewEngine()
+	// requests, in-flight requests and latency are recorded for every handler.
+	e := echotool.NewEngine(echotool.WithREDMetrics(nil))
 
-	r.POST("/users", e.EchoHandler(CreateUser))
+	e.Add(r, http.MethodPost, "/users", CreateUser)
 
 	r.Start(":1323")
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/songzhaoliang/echotool"
@@ -17,13 +19,20 @@ func main() {
 	r.Use(echotool.SetRequestID(echotool.GetUUID))
 
 	e := echotool.NewEngine()
-	e.Use(CheckToken)
+	e.Use(Timing, CheckToken)
 
-	r.POST("/users", e.EchoHandler(CreateUser))
+	e.Add(r, http.MethodPost, "/users", CreateUser)
 
 	r.Start(":1323")
 }
 
+// Timing is an onion middleware which runs code after the handler.
+func Timing(c echo.Context, ec *echotool.Context) {
+	ec.Next()
+
+	fmt.Printf("%s cost %v with code %d\n", ec.GetHandlerName(), time.Since(ec.GetStartTime()), ec.GetCode())
+}
+
 func CheckToken(c echo.Context, ec *echotool.Context) {
 	token := echotool.MustHeaderString(c, "X-Token")
 	if token != "RightToken" {
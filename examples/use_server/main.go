@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool"
+	"github.com/songzhaoliang/echotool/diskqueue"
+)
+
+func main() {
+	var q diskqueue.DiskQueue
+
+	s := echotool.NewServer(echotool.WithDrainTimeout(time.Second * 10))
+	s.OnStart("diskqueue", func(ctx context.Context) (err error) {
+		q, err = diskqueue.NewDiskQueue()
+		return
+	}).OnStop("diskqueue", func(ctx context.Context) error {
+		return q.Close()
+	})
+
+	e := echotool.NewDefaultEngine()
+	e.Add(s.Echo(), http.MethodGet, "/hello", Hello)
+
+	if err := s.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func Hello(c echo.Context, ec *echotool.Context) {
+	ec.Finish(echotool.CodeOKZero, "hello")
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type UpdateUserReq struct {
+	ID   int64  `param:"id" valid:"gt=0"`
+	Name string `json:"name" valid:"required"`
+}
+
+type UpdateUserResp struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func main() {
+	echotool.RegisterErrorCode(ErrUserNotFound, echotool.CodeNotFound)
+
+	r := echo.New()
+	r.Use(echotool.SetRequestID(echotool.GetUUID))
+
+	e := echotool.NewDefaultEngine()
+
+	echotool.AddHandle(e, r, http.MethodPut, "/users/:id", UpdateUser)
+
+	r.Start(":1323")
+}
+
+func UpdateUser(ec *echotool.Context, req *UpdateUserReq) (*UpdateUserResp, error) {
+	if req.ID > 100 {
+		return nil, ErrUserNotFound
+	}
+
+	return &UpdateUserResp{
+		ID:   req.ID,
+		Name: req.Name,
+	}, nil
+}
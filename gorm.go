@@ -2,8 +2,10 @@ package echotool
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/songzhaoliang/echotool/trace"
 	"gorm.io/gorm"
 	gl "gorm.io/gorm/logger"
 )
@@ -29,24 +31,37 @@ func (l *GORMLogger) LogMode(level gl.LogLevel) gl.Interface {
 }
 
 func (l *GORMLogger) Info(ctx context.Context, format string, args ...interface{}) {
-	ec, _ := ctx.(*Context)
+	ec := FromContext(ctx)
 	CtxInfo(ec, l.addPrefix(format), args...)
 }
 
 func (l *GORMLogger) Warn(ctx context.Context, format string, args ...interface{}) {
-	ec, _ := ctx.(*Context)
+	ec := FromContext(ctx)
 	CtxWarn(ec, l.addPrefix(format), args...)
 }
 
 func (l *GORMLogger) Error(ctx context.Context, format string, args ...interface{}) {
-	ec, _ := ctx.(*Context)
+	ec := FromContext(ctx)
 	CtxError(ec, l.addPrefix(format), args...)
 }
 
+// Trace logs the sql, and records it as a child span of the span in ctx if any.
 func (l *GORMLogger) Trace(ctx context.Context, begin time.Time, f func() (string, int64), err error) {
 	tns := time.Since(begin)
 	tms := float64(tns.Nanoseconds()) / 1e6
 	sql, rows := f()
+
+	if _, span := trace.Start(ctx, "gorm "+sqlOperation(sql),
+		trace.WithKind(trace.KindClient),
+		trace.WithStartTime(begin),
+		trace.WithAttribute("db.statement", sql),
+		trace.WithAttribute("db.rows_affected", rows)); span != nil {
+		if err != gorm.ErrRecordNotFound {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+
 	switch {
 	case err != nil && (err != gorm.ErrRecordNotFound || !l.IgnoreNoRecord):
 		l.Error(ctx, "[%.3fms] [rows:%d] %s - %v", tms, rows, sql, err)
@@ -57,6 +72,15 @@ func (l *GORMLogger) Trace(ctx context.Context, begin time.Time, f func() (strin
 	}
 }
 
+// sqlOperation returns the first word of sql in upper case, e.g. "SELECT".
+func sqlOperation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if i := strings.IndexAny(sql, " \t\n"); i > 0 {
+		sql = sql[:i]
+	}
+	return strings.ToUpper(sql)
+}
+
 func (l *GORMLogger) addPrefix(msg string) string {
 	return l.Prefix + " " + msg
 }
@@ -0,0 +1,149 @@
+package echotool
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Go runs fn in a goroutine with a copy of ec, which keeps the log identity and values of ec
+// and is still valid after the handler returns. The copy is canceled with the request,
+// so fn should return once it is done if fn outlives the handler. Panics of fn are recovered and logged.
+func (ec *Context) Go(fn func(ec *Context)) {
+	gc := ec.derive(ec.context())
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logGoPanic(gc, r)
+			}
+		}()
+
+		fn(gc)
+	}()
+}
+
+// ParallelFunc is a run of ParallelCtx, ec is canceled once another run fails.
+type ParallelFunc func(ec *Context) (interface{}, error)
+
+// Parallel calls runs in goroutines and waits for them like errgroup, the results are in the order of runs.
+// The panics of runs are recovered as errors. Parallel always waits for all runs, since they may use ec.
+// The first error is returned as *EchotoolError, whose code is the one of ErrorCode with CodeInternalErr.
+// Use ParallelCtx for the runs which should stop early when another run fails.
+func (ec *Context) Parallel(runs ...RunFunc) ([]interface{}, error) {
+	fns := make([]ParallelFunc, 0, len(runs))
+	for _, run := range runs {
+		run := run
+		fns = append(fns, func(*Context) (interface{}, error) {
+			return run()
+		})
+	}
+	return ec.ParallelCtx(fns...)
+}
+
+// ParallelCtx is Parallel whose runs are called with a copy of ec, which is canceled by the first error
+// or with ec. If ec is canceled, ParallelCtx returns the error of ec once all runs return.
+func (ec *Context) ParallelCtx(runs ...ParallelFunc) ([]interface{}, error) {
+	if err := ec.Err(); err != nil {
+		return nil, toEchotoolError(err)
+	}
+
+	var (
+		results  = make([]interface{}, len(runs))
+		firstErr error
+		once     sync.Once
+		wg       sync.WaitGroup
+	)
+
+	gc, cancel := ec.WithCancel()
+	defer cancel()
+
+	for i, run := range runs {
+		wg.Add(1)
+		go func(i int, run ParallelFunc) {
+			defer wg.Done()
+
+			result, err := callRun(gc, run)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = result
+		}(i, run)
+	}
+
+	wg.Wait()
+
+	if err := ec.Err(); err != nil {
+		return nil, toEchotoolError(err)
+	}
+	if firstErr != nil {
+		return nil, toEchotoolError(firstErr)
+	}
+	return results, nil
+}
+
+// MustParallel panics *EchotoolError if Parallel fails.
+func (ec *Context) MustParallel(runs ...RunFunc) []interface{} {
+	results, err := ec.Parallel(runs...)
+	if err != nil {
+		panic(err)
+	}
+	return results
+}
+
+// MustParallelCtx panics *EchotoolError if ParallelCtx fails.
+func (ec *Context) MustParallelCtx(runs ...ParallelFunc) []interface{} {
+	results, err := ec.ParallelCtx(runs...)
+	if err != nil {
+		panic(err)
+	}
+	return results
+}
+
+func callRun(ec *Context, run ParallelFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoverGo(ec, r)
+		}
+	}()
+
+	return run(ec)
+}
+
+// recoverGo logs r with the stack and turns it into an error.
+// *EchotoolError is returned as it is, since it is raised by Must functions on purpose.
+func recoverGo(ec *Context, r interface{}) error {
+	if err, ok := r.(*EchotoolError); ok {
+		return err
+	}
+
+	CtxError(ec, "panic recovered in goroutine: %v\n%s", r, debug.Stack())
+
+	if err, ok := r.(error); ok {
+		return err
+	}
+	return fmt.Errorf("panic: %v", r)
+}
+
+// logGoPanic logs r recovered from Go, including *EchotoolError raised by Must functions, which is released.
+func logGoPanic(ec *Context, r interface{}) {
+	e, ok := r.(*EchotoolError)
+	if !ok {
+		_ = recoverGo(ec, r)
+		return
+	}
+
+	CtxError(ec, "panic recovered in goroutine: code:%d, err:%v\n%s", e.GetCode(), e.GetError(), debug.Stack())
+	ReleaseEchotoolError(e)
+}
+
+func toEchotoolError(err error) error {
+	if IsEchotoolError(err) {
+		return err
+	}
+	return AcquireEchotoolError(ErrorCode(err, CodeInternalErr), err)
+}
@@ -0,0 +1,194 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Go(t *testing.T) {
+	logs := observeLogs(t)
+
+	start, done := make(chan struct{}), make(chan struct{})
+	PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		ec.SetCustomValue("trace_id", "t1")
+		ec.Set("user", "peter")
+		ec.Go(func(ec *Context) {
+			<-start
+			CtxInfo(ec, "user %s", MustGet[string](ec, "user"))
+			close(done)
+		})
+		ec.Go(func(ec *Context) {
+			panic("boom")
+		})
+		ec.Go(func(ec *Context) {
+			MustDoCallback(func() (interface{}, error) {
+				return nil, errors.New("downstream failed")
+			}, CodeDownstreamErr)
+		})
+		ec.Finish(CodeOK, nil)
+	})
+
+	// the pooled Context has been reset when the goroutine logs.
+	close(start)
+	<-done
+	assert.Eventually(t, func() bool {
+		return logs.FilterMessageSnippet("panic recovered in goroutine: boom").Len() == 1 &&
+			logs.FilterMessageSnippet("downstream failed").Len() == 1
+	}, time.Second, time.Millisecond*10)
+
+	entries := logs.FilterMessage("user peter").All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "t1", entries[0].ContextMap()["trace_id"])
+}
+
+func TestContext_Parallel(t *testing.T) {
+	observeLogs(t)
+	errFailed := errors.New("failed")
+
+	cases := []struct {
+		name    string
+		runs    []RunFunc
+		results []interface{}
+		code    int
+		errMsg  string
+	}{
+		{
+			name: "ok",
+			runs: []RunFunc{
+				func() (interface{}, error) {
+					time.Sleep(time.Millisecond * 20)
+					return 1, nil
+				},
+				func() (interface{}, error) {
+					return 2, nil
+				},
+			},
+			results: []interface{}{1, 2},
+		},
+		{
+			name: "error",
+			runs: []RunFunc{
+				func() (interface{}, error) {
+					return 1, nil
+				},
+				func() (interface{}, error) {
+					return nil, errFailed
+				},
+			},
+			code:   CodeInternalErr,
+			errMsg: "failed",
+		},
+		{
+			name: "must panic",
+			runs: []RunFunc{
+				func() (interface{}, error) {
+					return MustDoCallback(func() (interface{}, error) {
+						return nil, errFailed
+					}, CodeBadRequest), nil
+				},
+			},
+			code:   CodeBadRequest,
+			errMsg: "failed",
+		},
+		{
+			name: "panic",
+			runs: []RunFunc{
+				func() (interface{}, error) {
+					panic("boom")
+				},
+			},
+			code:   CodeInternalErr,
+			errMsg: "panic: boom",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(_ echo.Context, ec *Context) {
+				results, err := ec.Parallel(c.runs...)
+				if c.code == 0 {
+					assert.Nil(t, err)
+					assert.Equal(t, c.results, results)
+					return
+				}
+
+				assert.Nil(t, results)
+				e, ok := err.(*EchotoolError)
+				assert.True(t, ok)
+				assert.Equal(t, c.code, e.GetCode())
+				assert.True(t, strings.Contains(e.GetError().Error(), c.errMsg))
+			})
+		})
+	}
+}
+
+func TestContext_ParallelCanceled(t *testing.T) {
+	PerformRequest(NewEngine(WithAborter(func(_ echo.Context, ec *Context) {
+		assert.Equal(t, CodeInternalErr, ec.GetCode())
+		assert.True(t, errors.Is(ec.GetError(), context.DeadlineExceeded))
+	})), httptest.NewRequest(http.MethodGet, "/", nil), func(_ echo.Context, ec *Context) {
+		tc, cancel := ec.WithTimeout(time.Millisecond * 20)
+		defer cancel()
+
+		// the runs are waited for, they see the cancellation of tc.
+		returned := false
+		start := time.Now()
+		defer func() {
+			assert.True(t, returned)
+			assert.Less(t, time.Since(start), time.Second)
+		}()
+		tc.MustParallelCtx(func(gc *Context) (interface{}, error) {
+			<-gc.Done()
+			returned = true
+			return nil, nil
+		})
+	})
+}
+
+func TestContext_ParallelCtx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(_ echo.Context, ec *Context) {
+		canceled := false
+		start := time.Now()
+		results, err := ec.ParallelCtx(
+			func(gc *Context) (interface{}, error) {
+				select {
+				case <-gc.Done():
+					canceled = true
+					return nil, gc.Err()
+				case <-time.After(time.Second * 5):
+					return 1, nil
+				}
+			},
+			func(gc *Context) (interface{}, error) {
+				time.Sleep(time.Millisecond * 10)
+				return nil, errFailed
+			},
+		)
+
+		assert.Nil(t, results)
+		assert.True(t, errors.Is(err, errFailed))
+		assert.True(t, canceled)
+		assert.Less(t, time.Since(start), time.Second)
+		// ec itself is not canceled.
+		assert.Nil(t, ec.Err())
+	})
+}
+
+func TestContext_Clone(t *testing.T) {
+	ec := &Context{}
+	ec.SetCustomValue("trace_id", "t1")
+
+	cc := ec.Clone()
+	cc.SetCustomValue("trace_id", "t2")
+	v, _ := ec.GetCustomValue("trace_id")
+	assert.Equal(t, "t1", v)
+}
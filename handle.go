@@ -0,0 +1,236 @@
+package echotool
+
+import (
+	"errors"
+	"mime"
+	"reflect"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/binder"
+	"github.com/songzhaoliang/echotool/validator"
+)
+
+const (
+	MIMEApplicationXProtobuf = "application/x-protobuf"
+	MIMEApplicationXMsgpack  = "application/x-msgpack"
+	MIMEApplicationYAML      = "application/yaml"
+	MIMEApplicationXYAML     = "application/x-yaml"
+	MIMETextYAML             = "text/yaml"
+)
+
+// TypedHandlerFunc is a handler with a bound request and a response.
+type TypedHandlerFunc[Req any, Resp any] func(*Context, *Req) (*Resp, error)
+
+// Handle returns an echo handler built by e for fn, see TypedHandler.
+// The bind flags which Req may be bound by are recorded for Routes, see AddHandle.
+func Handle[Req any, Resp any](e *Engine, fn TypedHandlerFunc[Req, Resp]) echo.HandlerFunc {
+	ri, handler := typedRoute(fn)
+	return e.echoHandler(ri, handler)
+}
+
+// AddHandle adds the handler built by Handle to r, and records the route with its method and path.
+func AddHandle[Req any, Resp any](e *Engine, r Router, method, path string, fn TypedHandlerFunc[Req, Resp]) *echo.Route {
+	ri, handler := typedRoute(fn)
+	return e.add(r, method, path, ri, handler)
+}
+
+func typedRoute[Req any, Resp any](fn TypedHandlerFunc[Req, Resp]) (*RouteInfo, HandlerFunc) {
+	spec := newBindSpec(reflect.TypeOf((*Req)(nil)).Elem())
+	return &RouteInfo{
+		Handler:   getFuncName(fn),
+		BindFlags: BindFlagNames(spec.declared()),
+		typed:     true,
+	}, typedHandler(spec, fn)
+}
+
+// TypedHandler adapts fn to HandlerFunc.
+// The bind flags are worked out from the tags of Req once, and the body binder is chosen
+// by Content-Type of each request. Req is validated if it has tag "valid", and authorized by
+// the attribute rules of the policy set by WithPolicy.
+// The response of fn is finished with the current code of Context (CodeOKZero by default),
+// and the error of fn is aborted with the code of *EchotoolError, the code registered by
+// RegisterErrorCode or CodeInternalErr.
+func TypedHandler[Req any, Resp any](fn TypedHandlerFunc[Req, Resp]) HandlerFunc {
+	return typedHandler(newBindSpec(reflect.TypeOf((*Req)(nil)).Elem()), fn)
+}
+
+func typedHandler[Req any, Resp any](spec *bindSpec, fn TypedHandlerFunc[Req, Resp]) HandlerFunc {
+	return func(c echo.Context, ec *Context) {
+		req := new(Req)
+		if err := Bind(c, req, spec.getFlag(c)); err != nil {
+			abortWithError(ec, err, CodeBindErr)
+			return
+		}
+		if err := ec.Authorize(req); err != nil {
+			abortWithError(ec, err, CodeForbidden)
+			return
+		}
+
+		resp, err := fn(ec, req)
+		if err != nil {
+			abortWithError(ec, err, CodeInternalErr)
+			return
+		}
+
+		if resp == nil {
+			ec.Finish(ec.GetCode(), nil)
+		} else {
+			ec.Finish(ec.GetCode(), resp)
+		}
+	}
+}
+
+type errorCode struct {
+	target error
+	code   int
+}
+
+var (
+	errorCodesLock sync.RWMutex
+	errorCodes     []errorCode
+)
+
+// RegisterErrorCode makes TypedHandler abort with code if the error matches target by errors.Is.
+func RegisterErrorCode(target error, code int) {
+	errorCodesLock.Lock()
+	defer errorCodesLock.Unlock()
+
+	errorCodes = append(errorCodes, errorCode{
+		target: target,
+		code:   code,
+	})
+}
+
+// ErrorCode returns the code of err, or defaultCode if err has no code.
+func ErrorCode(err error, defaultCode int) int {
+	var e *EchotoolError
+	if errors.As(err, &e) {
+		return e.GetCode()
+	}
+
+	errorCodesLock.RLock()
+	defer errorCodesLock.RUnlock()
+
+	for _, item := range errorCodes {
+		if errors.Is(err, item.target) {
+			return item.code
+		}
+	}
+	return defaultCode
+}
+
+func abortWithError(ec *Context, err error, defaultCode int) {
+	if e, ok := err.(*EchotoolError); ok {
+		ec.Abort(e.GetCode(), e.GetError())
+		ReleaseEchotoolError(e)
+		return
+	}
+
+	ec.Abort(ErrorCode(err, defaultCode), err)
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+type bindSpec struct {
+	flag    int
+	form    bool
+	message bool
+}
+
+func newBindSpec(rt reflect.Type) *bindSpec {
+	spec := &bindSpec{
+		message: reflect.PtrTo(rt).Implements(protoMessageType),
+	}
+	if rt.Kind() == reflect.Struct {
+		spec.parse(rt)
+	}
+	return spec
+}
+
+func (s *bindSpec) parse(rt reflect.Type) {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tagged := false
+		for tag, flag := range tagFlags {
+			if _, exists := field.Tag.Lookup(tag); exists {
+				s.flag |= flag
+				tagged = true
+			}
+		}
+		if _, exists := field.Tag.Lookup(binder.TagForm); exists {
+			s.form = true
+			tagged = true
+		}
+
+		if !tagged && field.Type.Kind() == reflect.Struct {
+			s.parse(field.Type)
+		}
+	}
+}
+
+var tagFlags = map[string]int{
+	binder.TagHeader:   BHeader,
+	binder.TagParam:    BParam,
+	binder.TagEnv:      BEnv,
+	binder.TagCookie:   BCookie,
+	validator.TagValid: BValidator,
+}
+
+// declared returns all flags which getFlag may return.
+func (s *bindSpec) declared() int {
+	flag := s.flag | BJSONBody | BXMLBody | BMsgpackBody | BYAMLBody
+	if s.message {
+		flag |= BProtobufBody
+	}
+	if s.form {
+		flag |= BFormQuery | BFormQueryBody | BFormMultipart
+	}
+	return flag
+}
+
+func (s *bindSpec) getFlag(c echo.Context) int {
+	flag := s.flag
+	req := c.Request()
+	if req.ContentLength == 0 || req.Body == nil {
+		if s.form {
+			flag |= BFormQuery
+		}
+		return flag
+	}
+
+	ctype, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	switch ctype {
+	case echo.MIMEApplicationJSON:
+		flag |= BJSONBody
+	case echo.MIMEApplicationXML, echo.MIMETextXML:
+		flag |= BXMLBody
+	case echo.MIMEApplicationProtobuf, MIMEApplicationXProtobuf:
+		if s.message {
+			flag |= BProtobufBody
+		}
+	case echo.MIMEApplicationMsgpack, MIMEApplicationXMsgpack:
+		flag |= BMsgpackBody
+	case MIMEApplicationYAML, MIMEApplicationXYAML, MIMETextYAML:
+		flag |= BYAMLBody
+	case echo.MIMEApplicationForm:
+		if s.form {
+			// the form binder reads both query and body.
+			return flag | BFormQueryBody
+		}
+	case echo.MIMEMultipartForm:
+		if s.form {
+			flag |= BFormMultipart
+		}
+	}
+
+	if s.form {
+		flag |= BFormQuery
+	}
+	return flag
+}
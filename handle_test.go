@@ -0,0 +1,98 @@
+package echotool
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var errUserNotFound = errors.New("user not found")
+
+type UpdateUserReq struct {
+	Token string `header:"X-Token" valid:"required"`
+	ID    int64  `param:"id"`
+	Name  string `json:"name"`
+	Force bool   `form:"force"`
+}
+
+type UpdateUserResp struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func UpdateUser(ec *Context, req *UpdateUserReq) (*UpdateUserResp, error) {
+	if req.ID == 0 {
+		return nil, errUserNotFound
+	}
+	return &UpdateUserResp{
+		ID:   req.ID,
+		Name: req.Name,
+	}, nil
+}
+
+func TestHandle(t *testing.T) {
+	RegisterErrorCode(errUserNotFound, CodeNotFound)
+	h := Handle(NewEngine(), UpdateUser)
+
+	rec, c := newHandleContext(`{"name":"peter"}`, "1")
+	c.Request().Header.Set("X-Token", "token")
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"data":{"id":1,"name":"peter"}`)
+
+	rec, c = newHandleContext(`{"name":"peter"}`, "0")
+	c.Request().Header.Set("X-Token", "token")
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, c = newHandleContext(`{"name":"peter"}`, "1")
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), CodeMsg(CodeValidateErr))
+}
+
+func TestHandle_BodyLimit(t *testing.T) {
+	e := NewEngine()
+	e.Use(BodyLimit(16))
+	h := Handle(e, UpdateUser)
+
+	rec, c := newHandleContext(`{"name":"a long name exceeding the limit"}`, "1")
+	c.Request().Header.Set("X-Token", "token")
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":41300`)
+
+	rec, c = newHandleContext(`{"name":"peter"}`, "1")
+	c.Request().Header.Set("X-Token", "token")
+	assert.NoError(t, h(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestBindSpec(t *testing.T) {
+	spec := newBindSpec(reflect.TypeOf(UpdateUserReq{}))
+	assert.Equal(t, BHeader|BParam|BValidator, spec.flag)
+	assert.True(t, spec.form)
+
+	_, c := newHandleContext(`{}`, "1")
+	assert.Equal(t, BHeader|BParam|BValidator|BJSONBody|BFormQuery, spec.getFlag(c))
+
+	c.Request().Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	assert.Equal(t, BHeader|BParam|BValidator|BFormQueryBody, spec.getFlag(c))
+}
+
+func newHandleContext(body, id string) (*httptest.ResponseRecorder, echo.Context) {
+	req := httptest.NewRequest(http.MethodPut, "/users/"+id+"?force=true", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	return rec, c
+}
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQueueTooDeep = errors.New("queue is too deep")
+	ErrDiskSpaceLow = errors.New("disk space is low")
+	ErrNotSupported = errors.New("not supported")
+)
+
+// GORM pings the database of db.
+func GORM(db *gorm.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
+
+// Redis pings client.
+func Redis(client redis.UniversalClient) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
+
+// Queue is a queue with depth, e.g. diskqueue.DiskQueue.
+type Queue interface {
+	Len() int64
+}
+
+// DiskQueueDepth fails if q holds more than max messages.
+func DiskQueueDepth(q Queue, max int64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if depth := q.Len(); depth > max {
+			return fmt.Errorf("%w: %d > %d", ErrQueueTooDeep, depth, max)
+		}
+		return nil
+	})
+}
+
+// DiskSpace fails if the free space of the file system of path is less than min bytes.
+func DiskSpace(path string, min uint64) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		free, err := freeSpace(path)
+		if err != nil {
+			return err
+		}
+		if free < min {
+			return fmt.Errorf("%w: %d < %d bytes free in %s", ErrDiskSpaceLow, free, min, path)
+		}
+		return nil
+	})
+}
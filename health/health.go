@@ -0,0 +1,236 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/util"
+)
+
+const (
+	DefaultPrefix   = "/health"
+	DefaultTimeout  = time.Second * 2
+	DefaultCacheTTL = time.Second
+
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker checks a dependency, it should return once ctx is done.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+var _ Checker = (CheckerFunc)(nil)
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	timeout  time.Duration
+	cacheTTL time.Duration
+	liveness bool
+
+	lock      sync.Mutex
+	result    *Result
+	checkedAt time.Time
+}
+
+type CheckOption func(*check)
+
+// WithTimeout sets the timeout of the check, DefaultTimeout by default.
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+// WithCacheTTL sets how long the result of the check is reused, DefaultCacheTTL by default and 0 means no cache.
+func WithCacheTTL(ttl time.Duration) CheckOption {
+	return func(c *check) {
+		if ttl >= 0 {
+			c.cacheTTL = ttl
+		}
+	}
+}
+
+// WithLiveness makes the check a part of liveness as well as readiness.
+// Liveness should only check what can not be recovered without restarting.
+func WithLiveness() CheckOption {
+	return func(c *check) {
+		c.liveness = true
+	}
+}
+
+// Result is the result of a check.
+type Result struct {
+	Status    string        `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report is the aggregated result of checks, it is down if any check is down.
+type Report struct {
+	Status string             `json:"status"`
+	Checks map[string]*Result `json:"checks"`
+}
+
+var (
+	checksLock sync.RWMutex
+	checks     = make(map[string]*check)
+)
+
+// AddChecker adds a readiness checker named name, the checker of the same name is replaced.
+func AddChecker(name string, checker Checker, opts ...CheckOption) {
+	c := &check{
+		name:     name,
+		checker:  checker,
+		timeout:  DefaultTimeout,
+		cacheTTL: DefaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	checksLock.Lock()
+	defer checksLock.Unlock()
+
+	checks[name] = c
+}
+
+func RemoveChecker(name string) {
+	checksLock.Lock()
+	defer checksLock.Unlock()
+
+	delete(checks, name)
+}
+
+// Live runs the liveness checks.
+func Live(ctx context.Context) *Report {
+	return run(ctx, true)
+}
+
+// Ready runs all checks.
+func Ready(ctx context.Context) *Report {
+	return run(ctx, false)
+}
+
+func run(ctx context.Context, liveness bool) *Report {
+	checksLock.RLock()
+	selected := make([]*check, 0, len(checks))
+	for _, c := range checks {
+		if !liveness || c.liveness {
+			selected = append(selected, c)
+		}
+	}
+	checksLock.RUnlock()
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].name < selected[j].name
+	})
+
+	report := &Report{
+		Status: StatusUp,
+		Checks: make(map[string]*Result, len(selected)),
+	}
+
+	var (
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+	for _, c := range selected {
+		wg.Add(1)
+		go func(c *check) {
+			defer wg.Done()
+
+			result := c.run(ctx)
+
+			lock.Lock()
+			defer lock.Unlock()
+			report.Checks[c.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (c *check) run(ctx context.Context) *Result {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	if c.result != nil && now.Sub(c.checkedAt) < c.cacheTTL {
+		return c.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- c.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// the checker does not respect ctx.
+		err = ctx.Err()
+	}
+
+	result := &Result{
+		Status:    StatusUp,
+		Duration:  time.Since(now),
+		CheckedAt: now,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	c.result, c.checkedAt = result, now
+	return result
+}
+
+// Register mounts GET /health/live and /health/ready, which respond 200 if all checks are up, or 503.
+func Register(r *echo.Echo, prefixes ...string) {
+	register(r.Group(util.GetPrefix(append(prefixes, DefaultPrefix)...)))
+}
+
+func RouterRegister(g *echo.Group, prefixes ...string) {
+	register(g.Group(util.GetPrefix(append(prefixes, DefaultPrefix)...)))
+}
+
+func register(g *echo.Group) {
+	g.GET("/live", func(c echo.Context) error {
+		return respond(c, Live(c.Request().Context()))
+	})
+	g.GET("/ready", func(c echo.Context) error {
+		return respond(c, Ready(c.Request().Context()))
+	})
+}
+
+func respond(c echo.Context, report *Report) error {
+	status := http.StatusOK
+	if report.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func resetCheckers(t *testing.T) {
+	checksLock.Lock()
+	checks = make(map[string]*check)
+	checksLock.Unlock()
+	t.Cleanup(func() {
+		checksLock.Lock()
+		checks = make(map[string]*check)
+		checksLock.Unlock()
+	})
+}
+
+func get(r *echo.Echo, path string) (int, *Report) {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	report := &Report{}
+	_ = json.Unmarshal(rec.Body.Bytes(), report)
+	return rec.Code, report
+}
+
+func TestRegister(t *testing.T) {
+	resetCheckers(t)
+	r := echo.New()
+	Register(r)
+
+	var down int32
+	AddChecker("db", CheckerFunc(func(ctx context.Context) error {
+		if atomic.LoadInt32(&down) == 1 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}), WithCacheTTL(0))
+	AddChecker("process", CheckerFunc(func(ctx context.Context) error {
+		return nil
+	}), WithLiveness())
+
+	code, report := get(r, "/health/ready")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Len(t, report.Checks, 2)
+
+	atomic.StoreInt32(&down, 1)
+	code, report = get(r, "/health/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["db"].Error)
+	assert.Equal(t, StatusUp, report.Checks["process"].Status)
+
+	// liveness does not depend on db.
+	code, report = get(r, "/health/live")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, report.Checks, 1)
+}
+
+func TestCheck_Timeout(t *testing.T) {
+	resetCheckers(t)
+	AddChecker("slow", CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}), WithTimeout(time.Millisecond*10))
+
+	start := time.Now()
+	report := Ready(context.Background())
+	assert.Less(t, time.Since(start), time.Millisecond*500)
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestCheck_Cache(t *testing.T) {
+	resetCheckers(t)
+
+	var calls int32
+	AddChecker("cached", CheckerFunc(func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}), WithCacheTTL(time.Hour))
+
+	Ready(context.Background())
+	Ready(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+type queue int64
+
+func (q queue) Len() int64 {
+	return int64(q)
+}
+
+func TestDiskQueueDepth(t *testing.T) {
+	assert.Nil(t, DiskQueueDepth(queue(10), 10).Check(context.Background()))
+	assert.ErrorIs(t, DiskQueueDepth(queue(11), 10).Check(context.Background()), ErrQueueTooDeep)
+}
+
+func TestDiskSpace(t *testing.T) {
+	assert.Nil(t, DiskSpace(t.TempDir(), 1).Check(context.Background()))
+	assert.ErrorIs(t, DiskSpace(t.TempDir(), math.MaxUint64).Check(context.Background()), ErrDiskSpaceLow)
+}
@@ -0,0 +1,233 @@
+package echotool
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/binder"
+	"github.com/songzhaoliang/echotool/idempotency"
+	"github.com/songzhaoliang/echotool/json"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotency-Replayed"
+)
+
+var (
+	ErrIdempotencyKeyInFlight = errors.New("request of idempotency key is in flight")
+	ErrIdempotencyKeyReused   = errors.New("idempotency key is reused with a different request")
+)
+
+// IdempotencyConfig is the config of Idempotency.
+type IdempotencyConfig struct {
+	Header string
+	// TTL is how long the response of a key is replayed.
+	TTL time.Duration
+	// LockTTL is how long a key is locked by the request in flight, in case the request never ends.
+	LockTTL time.Duration
+	// StoreTimeout bounds saving or deleting the record after the handler,
+	// which is done even if the client goes away.
+	StoreTimeout time.Duration
+	// Scope returns the namespace of keys, the handler name by default.
+	Scope func(echo.Context, *Context) string
+}
+
+type IdempotencyOption func(*IdempotencyConfig)
+
+func WithIdempotencyHeader(header string) IdempotencyOption {
+	return func(cfg *IdempotencyConfig) {
+		if header != "" {
+			cfg.Header = header
+		}
+	}
+}
+
+func WithIdempotencyTTL(ttl time.Duration) IdempotencyOption {
+	return func(cfg *IdempotencyConfig) {
+		if ttl > 0 {
+			cfg.TTL = ttl
+		}
+	}
+}
+
+func WithIdempotencyLockTTL(ttl time.Duration) IdempotencyOption {
+	return func(cfg *IdempotencyConfig) {
+		if ttl > 0 {
+			cfg.LockTTL = ttl
+		}
+	}
+}
+
+func WithIdempotencyStoreTimeout(timeout time.Duration) IdempotencyOption {
+	return func(cfg *IdempotencyConfig) {
+		if timeout > 0 {
+			cfg.StoreTimeout = timeout
+		}
+	}
+}
+
+func WithIdempotencyScope(scope func(echo.Context, *Context) string) IdempotencyOption {
+	return func(cfg *IdempotencyConfig) {
+		if scope != nil {
+			cfg.Scope = scope
+		}
+	}
+}
+
+// Idempotency returns an onion middleware which makes requests with header Idempotency-Key idempotent.
+// The key is locked by the first request, and the other requests of the key are aborted with
+// CodeConflict while it is in flight. The final code and data are stored in store unless the status is 5xx,
+// then they are replayed for the key with header Idempotency-Replayed. A key reused with a different
+// method, path or body is aborted with CodeUnprocessableEntity.
+// The body is read as binder.PrepareBody does, so BodyLimit should be used before it,
+// and the request whose body exceeds the limit is aborted with CodeRequestEntityTooLarge.
+// Requests without the header and requests failing in store are handled as usual.
+func Idempotency(store idempotency.Store, opts ...IdempotencyOption) HandlerFunc {
+	cfg := &IdempotencyConfig{
+		Header:       HeaderIdempotencyKey,
+		TTL:          time.Hour * 24,
+		LockTTL:      time.Minute,
+		StoreTimeout: time.Second * 5,
+		Scope: func(c echo.Context, ec *Context) string {
+			return ec.GetHandlerName()
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(c echo.Context, ec *Context) {
+		key := c.Request().Header.Get(cfg.Header)
+		if key == "" {
+			return
+		}
+		key = cfg.Scope(c, ec) + ":" + key
+
+		fingerprint, err := fingerprintRequest(c)
+		if err != nil {
+			abortWithError(ec, err, CodeBadRequest)
+			return
+		}
+
+		record, err := store.Lock(ec, key, fingerprint, cfg.LockTTL)
+		if err != nil {
+			CtxWarn(ec, "lock idempotency key %s failed: %v", key, err)
+			return
+		}
+
+		if record != nil {
+			switch {
+			case record.Fingerprint != fingerprint:
+				ec.Abort(CodeUnprocessableEntity, ErrIdempotencyKeyReused)
+			case record.Pending:
+				ec.Abort(CodeConflict, ErrIdempotencyKeyInFlight)
+			default:
+				replayRecord(c, ec, record)
+			}
+			return
+		}
+
+		ec.Next()
+
+		// the record must be stored even if the client goes away, or the retries are conflicted.
+		sc, cancel := ec.WithoutCancel().WithTimeout(cfg.StoreTimeout)
+		defer cancel()
+
+		record, err = newIdempotencyRecord(ec, fingerprint)
+		if err != nil || ec.IsStreamed() || record.Status >= http.StatusInternalServerError {
+			err = store.Delete(sc, key)
+		} else {
+			err = store.Save(sc, key, record, cfg.TTL)
+		}
+		if err != nil {
+			CtxWarn(ec, "store idempotency key %s failed: %v", key, err)
+		}
+	}
+}
+
+// fingerprintRequest hashes the method, path and body of the request, the body is restored for binders.
+// The body is decompressed and limited by binder.PrepareBody.
+func fingerprintRequest(c echo.Context) (string, error) {
+	req := c.Request()
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+
+	if raw, ok := GetRawBody(c); ok {
+		h.Write(raw)
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	if err := binder.PrepareBody(c); err != nil {
+		return "", bodyError(err)
+	}
+	if req = c.Request(); req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			req.Body.Close()
+			return "", bodyError(err)
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func newIdempotencyRecord(ec *Context, fingerprint string) (*idempotency.Record, error) {
+	record := &idempotency.Record{
+		Fingerprint: fingerprint,
+		OK:          ec.IsOK(),
+		Code:        ec.GetCode(),
+		Status:      HTTPStatus(ec.GetCode()),
+	}
+
+	if !record.OK {
+		if err := ec.GetError(); err != nil {
+			record.Message = err.Error()
+		}
+		return record, nil
+	}
+
+	if data := ec.GetData(); data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		record.Data = b
+	}
+	return record, nil
+}
+
+// replayRecord finishes or aborts by record, and the data is rendered from its json form.
+func replayRecord(c echo.Context, ec *Context, record *idempotency.Record) {
+	c.Response().Header().Set(HeaderIdempotencyReplayed, "true")
+
+	if !record.OK {
+		var err error
+		if record.Message != "" {
+			err = errors.New(record.Message)
+		}
+		ec.Abort(record.Code, err)
+		return
+	}
+
+	var data interface{}
+	if len(record.Data) > 0 {
+		if err := json.Unmarshal(record.Data, &data); err != nil {
+			ec.Abort(CodeInternalErr, err)
+			return
+		}
+	}
+
+	ec.Finish(record.Code, data)
+	ec.AbortChain()
+}
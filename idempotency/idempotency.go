@@ -0,0 +1,30 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Record is the state of an idempotency key.
+type Record struct {
+	Fingerprint string `json:"fingerprint"`
+	// Pending is true while the first request of the key is in flight.
+	Pending bool            `json:"pending"`
+	OK      bool            `json:"ok"`
+	Code    int             `json:"code"`
+	Status  int             `json:"status"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+// Store keeps records of idempotency keys, it must be safe for concurrent use.
+type Store interface {
+	// Lock creates a pending record of key with fingerprint for ttl and returns nil if key does not exist,
+	// otherwise it returns the existing record.
+	Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+	// Save replaces the record of key for ttl.
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Delete deletes key, so the next request of key is handled again.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	sweepInterval = time.Minute
+)
+
+type entry struct {
+	record    *Record
+	expiresAt time.Time
+}
+
+// MemoryStore keeps records in memory, which is only suitable for a single node.
+type MemoryStore struct {
+	lock      sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*entry),
+	}
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if e, exists := s.entries[key]; exists && now.Before(e.expiresAt) {
+		record := *e.record
+		return &record, nil
+	}
+
+	s.entries[key] = &entry{
+		record: &Record{
+			Fingerprint: fingerprint,
+			Pending:     true,
+		},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	copied := *record
+	s.entries[key] = &entry{
+		record:    &copied,
+		expiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep deletes the expired records at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	record, err := s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+
+	record, err = s.Lock(ctx, "k", "other", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, record.Pending)
+	assert.Equal(t, "fp", record.Fingerprint)
+
+	assert.Nil(t, s.Save(ctx, "k", &Record{Fingerprint: "fp", OK: true, Code: 20000}, time.Minute))
+	record, err = s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, record.Pending)
+	assert.Equal(t, 20000, record.Code)
+
+	assert.Nil(t, s.Delete(ctx, "k"))
+	record, err = s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+}
+
+func TestMemoryStore_Expire(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	_, _ = s.Lock(ctx, "k", "fp", time.Millisecond)
+	time.Sleep(time.Millisecond * 2)
+
+	record, err := s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/songzhaoliang/echotool/json"
+)
+
+const (
+	DefaultRedisPrefix = "idempotency:"
+)
+
+var lockScript = redis.NewScript(`
+local record = redis.call("GET", KEYS[1])
+if record then
+	return record
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return false
+`)
+
+// RedisStore keeps records in redis, which is shared by a fleet.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client redis.UniversalClient, prefixes ...string) *RedisStore {
+	prefix := DefaultRedisPrefix
+	if len(prefixes) > 0 {
+		prefix = prefixes[0]
+	}
+
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	pending, err := json.Marshal(&Record{
+		Fingerprint: fingerprint,
+		Pending:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := lockScript.Run(ctx, s.client, []string{s.prefix + key}, pending, ttl.Milliseconds()).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{}
+	if err = json.Unmarshal([]byte(v), record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (s *RedisStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, s.prefix+key, b, ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
@@ -0,0 +1,68 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return NewRedisStore(client), mr
+}
+
+func TestRedisStore(t *testing.T) {
+	s, mr := newTestRedisStore(t)
+	ctx := context.Background()
+
+	record, err := s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+	assert.True(t, mr.Exists(DefaultRedisPrefix+"k"))
+	assert.Equal(t, time.Minute, mr.TTL(DefaultRedisPrefix+"k"))
+
+	record, err = s.Lock(ctx, "k", "other", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, record.Pending)
+	assert.Equal(t, "fp", record.Fingerprint)
+
+	assert.Nil(t, s.Save(ctx, "k", &Record{Fingerprint: "fp", OK: true, Code: 20000}, time.Hour))
+	assert.Equal(t, time.Hour, mr.TTL(DefaultRedisPrefix+"k"))
+	record, err = s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, record.Pending)
+	assert.Equal(t, 20000, record.Code)
+
+	assert.Nil(t, s.Delete(ctx, "k"))
+	record, err = s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+}
+
+func TestRedisStore_Expire(t *testing.T) {
+	s, mr := newTestRedisStore(t)
+	ctx := context.Background()
+
+	_, _ = s.Lock(ctx, "k", "fp", time.Second)
+	mr.FastForward(time.Second * 2)
+
+	record, err := s.Lock(ctx, "k", "fp", time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+}
+
+func TestRedisStore_Error(t *testing.T) {
+	s, mr := newTestRedisStore(t)
+	mr.Close()
+
+	_, err := s.Lock(context.Background(), "k", "fp", time.Minute)
+	assert.NotNil(t, err)
+}
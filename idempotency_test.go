@@ -0,0 +1,165 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	e := NewEngine()
+	middleware := Idempotency(idempotency.NewMemoryStore())
+
+	calls := 0
+	createUser := func(c echo.Context, ec *Context) {
+		calls++
+		body, _ := io.ReadAll(c.Request().Body)
+		ec.Finish(CodeCreated, map[string]interface{}{"id": calls, "body": string(body)})
+	}
+
+	perform := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		return PerformRequest(e, req, middleware, createUser)
+	}
+
+	first := perform("k1", "alice")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Contains(t, first.Body.String(), `"body":"alice"`)
+
+	replayed := perform("k1", "alice")
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "true", replayed.Header().Get(HeaderIdempotencyReplayed))
+	assert.JSONEq(t, first.Body.String(), replayed.Body.String())
+	assert.Equal(t, 1, calls)
+
+	reused := perform("k1", "bob")
+	assert.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, http.StatusCreated, perform("k2", "alice").Code)
+	assert.Equal(t, http.StatusCreated, perform("", "alice").Code)
+	assert.Equal(t, 3, calls)
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	e := NewEngine()
+	middleware := Idempotency(idempotency.NewMemoryStore())
+
+	var nested *httptest.ResponseRecorder
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+	req.Header.Set(HeaderIdempotencyKey, "k")
+	PerformRequest(e, req, middleware, func(c echo.Context, ec *Context) {
+		again := httptest.NewRequest(http.MethodPost, "/users", nil)
+		again.Header.Set(HeaderIdempotencyKey, "k")
+		nested = PerformRequest(e, again, middleware, func(c echo.Context, ec *Context) {
+			ec.Finish(CodeOKZero, nil)
+		})
+		ec.Finish(CodeOKZero, nil)
+	})
+
+	assert.Equal(t, http.StatusConflict, nested.Code)
+}
+
+func TestIdempotency_ServerError(t *testing.T) {
+	e := NewEngine()
+	middleware := Idempotency(idempotency.NewMemoryStore())
+
+	calls := 0
+	perform := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", nil)
+		req.Header.Set(HeaderIdempotencyKey, "k")
+		return PerformRequest(e, req, middleware, func(c echo.Context, ec *Context) {
+			calls++
+			ec.Abort(CodeInternalErr, errors.New("db down"))
+		})
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, perform().Code)
+	assert.Equal(t, http.StatusInternalServerError, perform().Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_BodyLimit(t *testing.T) {
+	e := NewEngine()
+	middleware := Idempotency(idempotency.NewMemoryStore())
+
+	var body []byte
+	perform := func(s string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(s))
+		req.Header.Set(HeaderIdempotencyKey, s)
+		// the length is unknown, so the body must be limited while reading.
+		req.ContentLength = -1
+		return PerformRequest(e, req, BodyLimit(5), middleware, func(c echo.Context, ec *Context) {
+			body, _ = io.ReadAll(c.Request().Body)
+			ec.Finish(CodeOKZero, nil)
+		})
+	}
+
+	rec := perform("alice")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice", string(body))
+
+	body = nil
+	rec = perform("alice and bob")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Nil(t, body)
+}
+
+// ctxStore fails once ctx is done, as RedisStore does.
+type ctxStore struct {
+	idempotency.Store
+}
+
+func (s ctxStore) Save(ctx context.Context, key string, record *idempotency.Record, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store.Save(ctx, key, record, ttl)
+}
+
+func (s ctxStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Store.Delete(ctx, key)
+}
+
+func TestIdempotency_ClientCanceled(t *testing.T) {
+	e := NewEngine()
+	middleware := Idempotency(ctxStore{idempotency.NewMemoryStore()})
+
+	calls := 0
+	perform := func(cancelClient bool) *httptest.ResponseRecorder {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil).WithContext(ctx)
+		req.Header.Set(HeaderIdempotencyKey, "k")
+		return PerformRequest(e, req, middleware, func(c echo.Context, ec *Context) {
+			calls++
+			if cancelClient {
+				// the client goes away after the order is created.
+				cancel()
+			}
+			ec.Finish(CodeCreated, nil)
+		})
+	}
+
+	perform(true)
+	rec := perform(false)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(HeaderIdempotencyReplayed))
+	assert.Equal(t, 1, calls)
+}
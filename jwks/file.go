@@ -0,0 +1,112 @@
+package jwks
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCheckInterval = time.Second * 10
+)
+
+// FileKeySet is a KeySet loaded from a local JWKS file.
+// The file is reloaded once its modification time changes, which is checked at most once per interval.
+// The loaded keys are kept if the file fails to be reloaded, and the error is returned by Err.
+type FileKeySet struct {
+	path     string
+	interval time.Duration
+
+	lock      sync.RWMutex
+	keys      map[string]*key
+	modTime   time.Time
+	lastCheck time.Time
+	err       error
+}
+
+var _ KeySet = (*FileKeySet)(nil)
+
+func NewFileKeySet(path string, intervals ...time.Duration) (*FileKeySet, error) {
+	interval := DefaultCheckInterval
+	if len(intervals) > 0 {
+		interval = intervals[0]
+	}
+
+	s := &FileKeySet{
+		path:     path,
+		interval: interval,
+	}
+	if err := s.load(time.Now()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileKeySet) Key(kid, alg string) (interface{}, error) {
+	s.reload()
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return lookup(s.keys, kid, alg)
+}
+
+// Err returns the error of the last reload.
+func (s *FileKeySet) Err() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.err
+}
+
+func (s *FileKeySet) reload() {
+	now := time.Now()
+
+	s.lock.RLock()
+	due := now.Sub(s.lastCheck) >= s.interval
+	s.lock.RUnlock()
+	if !due {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// checked by another goroutine.
+	if now.Sub(s.lastCheck) < s.interval {
+		return
+	}
+	s.err = s.loadLocked(now)
+}
+
+func (s *FileKeySet) load(now time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.loadLocked(now)
+}
+
+func (s *FileKeySet) loadLocked(now time.Time) error {
+	s.lastCheck = now
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if s.keys != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	keys, err := parse(b)
+	if err != nil {
+		return err
+	}
+
+	s.keys = keys
+	s.modTime = info.ModTime()
+	return nil
+}
@@ -0,0 +1,79 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestFileKeySet(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	content := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa","alg":"RS256","use":"sig","n":%q,"e":"AQAB"},
+		{"kty":"EC","kid":"ec","crv":"P-256","x":%q,"y":%q},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"","e":""}
+	]}`, encode(rsaKey.N.Bytes()), encode(ecKey.X.Bytes()), encode(ecKey.Y.Bytes()))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+	s, err := NewFileKeySet(path, time.Millisecond)
+	assert.Nil(t, err)
+
+	key, err := s.Key("rsa", "RS256")
+	assert.Nil(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(key))
+
+	_, err = s.Key("rsa", "HS256")
+	assert.ErrorIs(t, err, ErrAlgNotMatches)
+
+	key, err = s.Key("ec", "ES256")
+	assert.Nil(t, err)
+	assert.True(t, ecKey.PublicKey.Equal(key))
+
+	_, err = s.Key("enc", "RS256")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	// the file is reloaded after it is modified.
+	assert.Nil(t, os.WriteFile(path, []byte(`{"keys":[{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`), 0644))
+	assert.Nil(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(time.Millisecond * 2)
+
+	key, err = s.Key("hmac", "HS256")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("secret"), key)
+
+	// the keys are kept if the file is broken.
+	assert.Nil(t, os.WriteFile(path, []byte(`{`), 0644))
+	assert.Nil(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second*2)))
+	time.Sleep(time.Millisecond * 2)
+
+	_, err = s.Key("hmac", "HS256")
+	assert.Nil(t, err)
+	assert.NotNil(t, s.Err())
+}
+
+func TestStaticKeySet(t *testing.T) {
+	s := StaticKeySet{"": []byte("secret")}
+
+	key, err := s.Key("any", "HS256")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("secret"), key)
+
+	_, err = StaticKeySet{}.Key("any", "HS256")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
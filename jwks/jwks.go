@@ -0,0 +1,149 @@
+package jwks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/songzhaoliang/echotool/json"
+)
+
+var (
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrAlgNotMatches    = errors.New("alg not matches")
+	ErrUnsupportedKey   = errors.New("unsupported key")
+	ErrUnsupportedCurve = errors.New("unsupported curve")
+)
+
+// KeySet returns the key verifying tokens signed by alg with key id kid.
+// The key is []byte for HMAC, *rsa.PublicKey for RSA and *ecdsa.PublicKey for ECDSA.
+type KeySet interface {
+	Key(kid, alg string) (interface{}, error)
+}
+
+// StaticKeySet is a KeySet by key id, the key of empty id is used if kid is not found.
+type StaticKeySet map[string]interface{}
+
+var _ KeySet = (StaticKeySet)(nil)
+
+func (s StaticKeySet) Key(kid, alg string) (interface{}, error) {
+	if key, exists := s[kid]; exists {
+		return key, nil
+	}
+	if key, exists := s[""]; exists {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+}
+
+// JWK is a JSON Web Key defined by RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// oct
+	K string `json:"k"`
+}
+
+type key struct {
+	alg   string
+	value interface{}
+}
+
+// parse parses a JSON Web Key Set, the keys not for signature are skipped.
+func parse(b []byte) (map[string]*key, error) {
+	var set struct {
+		Keys []*JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*key, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		value, err := jwk.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = &key{
+			alg:   jwk.Alg,
+			value: value,
+		}
+	}
+	return keys, nil
+}
+
+// PublicKey returns the key verifying signatures.
+func (k *JWK) PublicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(k.K)
+	}
+	return nil, fmt.Errorf("%w: kty %s", ErrUnsupportedKey, k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func lookup(keys map[string]*key, kid, alg string) (interface{}, error) {
+	k, exists := keys[kid]
+	if !exists {
+		return nil, fmt.Errorf("%w: kid %q", ErrKeyNotFound, kid)
+	}
+	if k.alg != "" && k.alg != alg {
+		return nil, fmt.Errorf("%w: kid %q is for %s", ErrAlgNotMatches, kid, k.alg)
+	}
+	return k.value, nil
+}
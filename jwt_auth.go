@@ -0,0 +1,171 @@
+package echotool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/jwks"
+)
+
+const (
+	KeyJWTClaims = "jwt_claims"
+)
+
+var (
+	ErrTokenMissing      = errors.New("token is missing")
+	ErrTokenSchemeNotMet = errors.New("authorization scheme is not bearer")
+)
+
+// JWTConfig is the config of JWTAuth.
+type JWTConfig struct {
+	// Cookie is the name of the cookie carrying the token if there is no header Authorization.
+	Cookie string
+	// Algs are the accepted signing algorithms.
+	Algs     []string
+	Issuer   string
+	Audience string
+	// Leeway is the clock skew allowed when checking exp and nbf.
+	Leeway time.Duration
+	// ExpirationOptional accepts the tokens without exp, which never expire.
+	ExpirationOptional bool
+	// Notices maps claims to custom values of Context, so that they are printed in logs.
+	Notices map[string]string
+}
+
+type JWTOption func(*JWTConfig)
+
+func WithJWTCookie(name string) JWTOption {
+	return func(cfg *JWTConfig) {
+		cfg.Cookie = name
+	}
+}
+
+func WithJWTAlgs(algs ...string) JWTOption {
+	return func(cfg *JWTConfig) {
+		if len(algs) > 0 {
+			cfg.Algs = algs
+		}
+	}
+}
+
+func WithJWTIssuer(issuer string) JWTOption {
+	return func(cfg *JWTConfig) {
+		cfg.Issuer = issuer
+	}
+}
+
+func WithJWTAudience(audience string) JWTOption {
+	return func(cfg *JWTConfig) {
+		cfg.Audience = audience
+	}
+}
+
+func WithJWTLeeway(leeway time.Duration) JWTOption {
+	return func(cfg *JWTConfig) {
+		if leeway >= 0 {
+			cfg.Leeway = leeway
+		}
+	}
+}
+
+// WithJWTExpirationOptional accepts the tokens without exp, which are rejected by default.
+func WithJWTExpirationOptional() JWTOption {
+	return func(cfg *JWTConfig) {
+		cfg.ExpirationOptional = true
+	}
+}
+
+// WithJWTNotice puts claim into the custom value key of Context.
+func WithJWTNotice(claim, key string) JWTOption {
+	return func(cfg *JWTConfig) {
+		cfg.Notices[claim] = key
+	}
+}
+
+// JWTAuth returns a handler which validates the bearer token of header Authorization, or the token of
+// the cookie set by WithJWTCookie. The token is signed by HS256, RS256 or ES256 with the key in keys
+// found by header kid, and exp, nbf, iss and aud are checked. exp is required unless WithJWTExpirationOptional is used.
+// The claims are saved in echo.Context and can be got by GetJWTClaims.
+// The request is aborted with CodeUnauthorized and the reason if the token is missing or invalid.
+func JWTAuth(keys jwks.KeySet, opts ...JWTOption) HandlerFunc {
+	cfg := &JWTConfig{
+		Algs:    []string{"HS256", "RS256", "ES256"},
+		Leeway:  time.Second * 30,
+		Notices: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(cfg.Algs),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if !cfg.ExpirationOptional {
+		parserOpts = append(parserOpts, jwt.WithExpirationRequired())
+	}
+	if cfg.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.Audience))
+	}
+	parser := jwt.NewParser(parserOpts...)
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.Key(kid, token.Method.Alg())
+	}
+
+	return func(c echo.Context, ec *Context) {
+		raw, err := extractToken(c, cfg.Cookie)
+		if err != nil {
+			ec.Abort(CodeUnauthorized, err)
+			return
+		}
+
+		claims := jwt.MapClaims{}
+		if _, err = parser.ParseWithClaims(raw, claims, keyFunc); err != nil {
+			ec.Abort(CodeUnauthorized, err)
+			return
+		}
+
+		c.Set(KeyJWTClaims, claims)
+		for claim, key := range cfg.Notices {
+			if value, exists := claims[claim]; exists {
+				ec.SetCustomValue(key, fmt.Sprint(value))
+			}
+		}
+	}
+}
+
+// GetJWTClaims returns the claims validated by JWTAuth.
+func GetJWTClaims(c echo.Context) jwt.MapClaims {
+	if claims, ok := c.Get(KeyJWTClaims).(jwt.MapClaims); ok {
+		return claims
+	}
+	return nil
+}
+
+func extractToken(c echo.Context, cookie string) (string, error) {
+	if auth := c.Request().Header.Get(echo.HeaderAuthorization); auth != "" {
+		scheme, token, found := strings.Cut(auth, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", ErrTokenSchemeNotMet
+		}
+		if token = strings.TrimSpace(token); token != "" {
+			return token, nil
+		}
+	}
+
+	if cookie != "" {
+		if ck, err := c.Cookie(cookie); err == nil && ck.Value != "" {
+			return ck.Value, nil
+		}
+	}
+	return "", ErrTokenMissing
+}
@@ -0,0 +1,136 @@
+package echotool
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/jwks"
+	"github.com/stretchr/testify/assert"
+)
+
+var jwtSecret = []byte("secret")
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	assert.Nil(t, err)
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	e := NewEngine()
+	auth := JWTAuth(jwks.StaticKeySet{"": jwtSecret},
+		WithJWTIssuer("echotool"),
+		WithJWTAudience("api"),
+		WithJWTCookie("token"),
+		WithJWTNotice("sub", "user"))
+
+	var (
+		claims jwt.MapClaims
+		user   string
+	)
+	handler := func(c echo.Context, ec *Context) {
+		claims = GetJWTClaims(c)
+		user, _ = ec.GetCustomValue("user")
+		ec.Finish(CodeOKZero, nil)
+	}
+
+	perform := func(set func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		set(req)
+		return PerformRequest(e, req, auth, handler)
+	}
+
+	valid := jwt.MapClaims{
+		"sub": "alice",
+		"iss": "echotool",
+		"aud": "api",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	rec := perform(func(req *http.Request) {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+signToken(t, valid))
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice", claims["sub"])
+	assert.Equal(t, "alice", user)
+
+	rec = perform(func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: "token", Value: signToken(t, valid)})
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = perform(func(req *http.Request) {})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrTokenMissing.Error())
+
+	rec = perform(func(req *http.Request) {
+		req.Header.Set(echo.HeaderAuthorization, "Basic YWxpY2U6")
+	})
+	assert.Contains(t, rec.Body.String(), ErrTokenSchemeNotMet.Error())
+
+	// expired within the leeway.
+	skewed := jwt.MapClaims{"iss": "echotool", "aud": "api", "exp": time.Now().Add(-time.Second * 10).Unix()}
+	rec = perform(func(req *http.Request) {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+signToken(t, skewed))
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	for reason, claims := range map[string]jwt.MapClaims{
+		"token is expired":                {"iss": "echotool", "aud": "api", "exp": time.Now().Add(-time.Hour).Unix()},
+		"token is not valid yet":          {"iss": "echotool", "aud": "api", "exp": exp, "nbf": time.Now().Add(time.Hour).Unix()},
+		"token has invalid issuer":        {"iss": "other", "aud": "api", "exp": exp},
+		"token has invalid audience":      {"iss": "echotool", "aud": "other", "exp": exp},
+		"token is missing required claim": {"iss": "echotool", "aud": "api"},
+	} {
+		rec = perform(func(req *http.Request) {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+signToken(t, claims))
+		})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), reason)
+	}
+}
+
+func TestJWTAuth_ES256(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	auth := JWTAuth(jwks.StaticKeySet{"ec": &key.PublicKey})
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "ec"
+	signed, err := token.SignedString(key)
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+signed)
+	rec := PerformRequest(NewEngine(), req, auth, func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// the HMAC token can not be verified by the public key.
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+signToken(t, jwt.MapClaims{"sub": "alice"}))
+	rec = PerformRequest(NewEngine(), req, auth)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestJWTAuth_ExpirationOptional(t *testing.T) {
+	handler := func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+signToken(t, jwt.MapClaims{"sub": "alice"}))
+
+	rec := PerformRequest(NewEngine(), req, JWTAuth(jwks.StaticKeySet{"": jwtSecret}), handler)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":40100`)
+	assert.Contains(t, rec.Body.String(), "exp claim is required")
+
+	rec = PerformRequest(NewEngine(), req, JWTAuth(jwks.StaticKeySet{"": jwtSecret}, WithJWTExpirationOptional()), handler)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -9,15 +9,17 @@ import (
 	rl "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/popeyeio/handy"
 	"github.com/songzhaoliang/echotool/json"
+	"github.com/songzhaoliang/echotool/loglevel"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 var logger = NewDefaultLogger()
 
+// NewDefaultLogger returns a logger whose level is the one shared by loglevel.AtomicLevel.
 func NewDefaultLogger() *zap.SugaredLogger {
 	cfg := zap.Config{
-		Level:            zap.NewAtomicLevelAt(zap.DebugLevel),
+		Level:            loglevel.AtomicLevel(),
 		Encoding:         "console",
 		EncoderConfig:    NewDefaultEncodeConfig(),
 		OutputPaths:      []string{"stdout"},
@@ -35,8 +37,10 @@ type RotateConfig struct {
 	Paths         []string
 	Suffix        string
 	Level         zapcore.Level
-	RotateTime    time.Duration
-	TTL           time.Duration
+	// SharedLevel makes the logger use the level shared by loglevel.AtomicLevel instead of its own.
+	SharedLevel bool
+	RotateTime  time.Duration
+	TTL         time.Duration
 }
 
 type RotateConfigOption func(*RotateConfig)
@@ -69,6 +73,14 @@ func WithLevel(level zapcore.Level) RotateConfigOption {
 	}
 }
 
+// WithSharedLevel makes the logger use the level shared by loglevel.AtomicLevel, which is set to
+// the level of config, so it can be changed at runtime by the endpoint of loglevel.
+func WithSharedLevel() RotateConfigOption {
+	return func(c *RotateConfig) {
+		c.SharedLevel = true
+	}
+}
+
 func WithRotateTime(t time.Duration) RotateConfigOption {
 	return func(c *RotateConfig) {
 		if t > 0 {
@@ -85,6 +97,8 @@ func WithTTL(ttl time.Duration) RotateConfigOption {
 	}
 }
 
+// NewRotateLogger returns a logger which rotates the files of paths.
+// It has its own level unless WithSharedLevel is used.
 func NewRotateLogger(opts ...RotateConfigOption) (*zap.SugaredLogger, error) {
 	cfg := &RotateConfig{
 		EncoderConfig: NewDefaultEncodeConfig(),
@@ -99,6 +113,10 @@ func NewRotateLogger(opts ...RotateConfigOption) (*zap.SugaredLogger, error) {
 	}
 
 	level := zap.NewAtomicLevelAt(cfg.Level)
+	if cfg.SharedLevel {
+		loglevel.SetLevel(cfg.Level)
+		level = loglevel.AtomicLevel()
+	}
 	enc := zapcore.NewConsoleEncoder(cfg.EncoderConfig)
 	var cores []zapcore.Core
 	for _, path := range cfg.Paths {
@@ -266,12 +284,40 @@ func buildLogger(ec *Context) (l *zap.SugaredLogger) {
 		l = l.Named(v)
 	}
 
-	for k, v := range ec.GetCustomValues() {
+	// customValues is read directly, so that no map is allocated for the logs.
+	for k, v := range ec.customValues {
 		l = l.With(zap.String(k, v))
 	}
+
+	if ec.IsDebugLog() {
+		l = l.Desugar().WithOptions(zap.WrapCore(newDebugCore)).Sugar()
+	}
 	return
 }
 
+// debugCore writes the entries of all levels, which is used for the requests set by DebugLog.
+type debugCore struct {
+	zapcore.Core
+}
+
+func newDebugCore(core zapcore.Core) zapcore.Core {
+	return &debugCore{
+		Core: core,
+	}
+}
+
+func (c *debugCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *debugCore) With(fields []zap.Field) zapcore.Core {
+	return newDebugCore(c.Core.With(fields))
+}
+
+func (c *debugCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
 func Debug(format string, args ...interface{}) {
 	logger.Debugf(format, args...)
 }
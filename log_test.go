@@ -0,0 +1,34 @@
+package echotool
+
+import (
+	"testing"
+
+	"github.com/songzhaoliang/echotool/loglevel"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewRotateLogger_Level(t *testing.T) {
+	origin := loglevel.Level()
+	t.Cleanup(func() {
+		loglevel.SetLevel(origin)
+	})
+	loglevel.SetLevel(zapcore.InfoLevel)
+
+	l, err := NewRotateLogger(WithLevel(zapcore.ErrorLevel))
+	assert.Nil(t, err)
+	assert.Equal(t, zapcore.InfoLevel, loglevel.Level())
+	assert.False(t, l.Desugar().Core().Enabled(zapcore.WarnLevel))
+
+	// the own level is not changed by loglevel.
+	loglevel.SetLevel(zapcore.DebugLevel)
+	assert.False(t, l.Desugar().Core().Enabled(zapcore.WarnLevel))
+
+	l, err = NewRotateLogger(WithLevel(zapcore.WarnLevel), WithSharedLevel())
+	assert.Nil(t, err)
+	assert.Equal(t, zapcore.WarnLevel, loglevel.Level())
+	assert.False(t, l.Desugar().Core().Enabled(zapcore.InfoLevel))
+
+	loglevel.SetLevel(zapcore.DebugLevel)
+	assert.True(t, l.Desugar().Core().Enabled(zapcore.DebugLevel))
+}
@@ -0,0 +1,162 @@
+package loglevel
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/util"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	DefaultPrefix = "/debug/loglevel"
+)
+
+var (
+	level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+
+	lock       sync.Mutex
+	timer      *time.Timer
+	generation uint64
+	revertTo   zapcore.Level
+	revertAt   time.Time
+)
+
+// AtomicLevel returns the level shared by the loggers of echotool, which can be changed at runtime.
+func AtomicLevel() zap.AtomicLevel {
+	return level
+}
+
+func Level() zapcore.Level {
+	return level.Level()
+}
+
+// SetLevel sets the level and cancels the pending revert if any.
+func SetLevel(l zapcore.Level) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	cancel()
+	level.SetLevel(l)
+}
+
+// SetLevelFor sets the level and reverts it after d.
+// If another revert is pending, the level is reverted to the one before that revert instead.
+func SetLevelFor(l zapcore.Level, d time.Duration) {
+	if d <= 0 {
+		SetLevel(l)
+		return
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	origin := level.Level()
+	if timer != nil {
+		origin = revertTo
+	}
+	cancel()
+
+	level.SetLevel(l)
+	revertTo, revertAt = origin, time.Now().Add(d)
+	gen := generation
+	timer = time.AfterFunc(d, func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if gen != generation {
+			return
+		}
+		level.SetLevel(revertTo)
+		timer, revertAt = nil, time.Time{}
+	})
+}
+
+// RevertAt returns when the level will be reverted, zero means no revert is pending.
+func RevertAt() time.Time {
+	lock.Lock()
+	defer lock.Unlock()
+	return revertAt
+}
+
+func cancel() {
+	generation++
+	if timer != nil {
+		timer.Stop()
+		timer = nil
+	}
+	revertAt = time.Time{}
+}
+
+// State is the response of the endpoints.
+type State struct {
+	Level    string     `json:"level"`
+	RevertTo string     `json:"revert_to,omitempty"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// Change is the request body of PUT, e.g. {"level":"debug","duration":"10m"}.
+// Duration is optional, the level is kept until the next change without it.
+type Change struct {
+	Level    string `json:"level"`
+	Duration string `json:"duration"`
+}
+
+func getState() *State {
+	lock.Lock()
+	defer lock.Unlock()
+
+	s := &State{
+		Level: level.Level().String(),
+	}
+	if !revertAt.IsZero() {
+		at := revertAt
+		s.RevertTo, s.RevertAt = revertTo.String(), &at
+	}
+	return s
+}
+
+// Register mounts GET and PUT /debug/loglevel, which read and change the level.
+// They have no authentication, so they should be mounted by RouterRegister with an authenticated group
+// unless r is only reachable internally.
+func Register(r *echo.Echo, prefixes ...string) {
+	register(r.Group(util.GetPrefix(append(prefixes, DefaultPrefix)...)))
+}
+
+func RouterRegister(g *echo.Group, prefixes ...string) {
+	register(g.Group(util.GetPrefix(append(prefixes, DefaultPrefix)...)))
+}
+
+func register(g *echo.Group) {
+	g.GET("", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, getState())
+	})
+	g.PUT("", func(c echo.Context) error {
+		change := &Change{}
+		if err := c.Bind(change); err != nil {
+			return err
+		}
+
+		var l zapcore.Level
+		if change.Level == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "level is required")
+		}
+		if err := l.UnmarshalText([]byte(change.Level)); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		var d time.Duration
+		if change.Duration != "" {
+			var err error
+			if d, err = time.ParseDuration(change.Duration); err != nil || d <= 0 {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid duration "+change.Duration)
+			}
+		}
+
+		SetLevelFor(l, d)
+		return c.JSON(http.StatusOK, getState())
+	})
+}
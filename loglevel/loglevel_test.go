@@ -0,0 +1,86 @@
+package loglevel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func do(r *echo.Echo, method, body string) (int, *State) {
+	req := httptest.NewRequest(method, DefaultPrefix, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	s := &State{}
+	_ = json.Unmarshal(rec.Body.Bytes(), s)
+	return rec.Code, s
+}
+
+func TestRegister(t *testing.T) {
+	origin := Level()
+	t.Cleanup(func() {
+		SetLevel(origin)
+	})
+	SetLevel(zapcore.InfoLevel)
+
+	r := echo.New()
+	Register(r)
+
+	code, s := do(r, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "info", s.Level)
+	assert.Nil(t, s.RevertAt)
+
+	code, s = do(r, http.MethodPut, `{"level":"warn"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "warn", s.Level)
+	assert.Equal(t, zapcore.WarnLevel, Level())
+
+	code, _ = do(r, http.MethodPut, `{"level":"verbose"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(r, http.MethodPut, `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(r, http.MethodPut, `{"level":"debug","duration":"-1s"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, zapcore.WarnLevel, Level())
+
+	code, s = do(r, http.MethodPut, `{"level":"debug","duration":"50ms"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "debug", s.Level)
+	assert.Equal(t, "warn", s.RevertTo)
+	assert.NotNil(t, s.RevertAt)
+
+	assert.Eventually(t, func() bool {
+		return Level() == zapcore.WarnLevel
+	}, time.Second, time.Millisecond*10)
+	assert.True(t, RevertAt().IsZero())
+}
+
+func TestSetLevelFor(t *testing.T) {
+	origin := Level()
+	t.Cleanup(func() {
+		SetLevel(origin)
+	})
+	SetLevel(zapcore.InfoLevel)
+
+	SetLevelFor(zapcore.DebugLevel, time.Hour)
+	SetLevelFor(zapcore.WarnLevel, time.Millisecond*50)
+	assert.Equal(t, zapcore.WarnLevel, Level())
+	assert.Eventually(t, func() bool {
+		return Level() == zapcore.InfoLevel
+	}, time.Second, time.Millisecond*10)
+
+	SetLevelFor(zapcore.DebugLevel, time.Millisecond*20)
+	SetLevel(zapcore.ErrorLevel)
+	time.Sleep(time.Millisecond * 60)
+	assert.Equal(t, zapcore.ErrorLevel, Level())
+	assert.True(t, RevertAt().IsZero())
+}
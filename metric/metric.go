@@ -123,6 +123,11 @@ func (c *MetricClient) DefineSummary(name string, parser LabelsParser, objective
 	return c.define(name, sv)
 }
 
+func (c *MetricClient) IsDefined(name string) bool {
+	_, exists := c.AllMetrics.Load(name)
+	return exists
+}
+
 func (c *MetricClient) define(name string, metric interface{}) error {
 	if _, exists := c.AllMetrics.LoadOrStore(name, metric); exists {
 		return ErrMetricExists
@@ -170,6 +175,27 @@ func (c *MetricClient) EmitGauge(name string, value float64, parser LabelsParser
 	return nil
 }
 
+// AddGauge adds value to the gauge, which can be negative.
+func (c *MetricClient) AddGauge(name string, value float64, parser LabelsParser) error {
+	metric, exists := c.AllMetrics.Load(name)
+	if !exists {
+		return ErrMetricNotExists
+	}
+
+	gv, ok := metric.(*prometheus.GaugeVec)
+	if !ok {
+		return ErrMetricTypeNotMatches
+	}
+
+	gauge, err := gv.GetMetricWith(parser.ParseToLabels())
+	if err != nil {
+		return err
+	}
+
+	gauge.Add(value)
+	return nil
+}
+
 func (c *MetricClient) EmitHistogram(name string, value float64, parser LabelsParser) error {
 	metric, exists := c.AllMetrics.Load(name)
 	if !exists {
@@ -235,6 +261,10 @@ func EmitGauge(name string, value float64, parser LabelsParser) error {
 	return DefaultMetricClient.EmitGauge(name, value, parser)
 }
 
+func AddGauge(name string, value float64, parser LabelsParser) error {
+	return DefaultMetricClient.AddGauge(name, value, parser)
+}
+
 func EmitHistogram(name string, value float64, parser LabelsParser) error {
 	return DefaultMetricClient.EmitHistogram(name, value, parser)
 }
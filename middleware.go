@@ -4,6 +4,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/popeyeio/handy"
+	"github.com/songzhaoliang/echotool/binder"
 	"moul.io/http2curl"
 )
 
@@ -54,8 +55,18 @@ func AddNotice(key, value string) HandlerFunc {
 	}
 }
 
+// BodyLimit limits the decompressed request body read by binders to limit bytes,
+// which overrides binder.MaxBodySize.
+func BodyLimit(limit int64) HandlerFunc {
+	return func(c echo.Context, ec *Context) {
+		c.Set(binder.KeyBodyLimit, limit)
+	}
+}
+
+// PrintRequest prints the request as a curl command, the body is read again if it is buffered by BufferBody.
 func PrintRequest() HandlerFunc {
 	return func(c echo.Context, ec *Context) {
+		rewindBody(c)
 		if cmd, err := http2curl.GetCurlCommand(c.Request()); err == nil {
 			CtxInfoKV(ec, cmd.String())
 		}
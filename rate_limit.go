@@ -0,0 +1,85 @@
+package echotool
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/ratelimit"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+var (
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// RateLimitKeyFunc returns the key of the request to be limited, the request is not limited if it is empty.
+type RateLimitKeyFunc func(echo.Context, *Context) string
+
+func RateLimitByIP() RateLimitKeyFunc {
+	return func(c echo.Context, ec *Context) string {
+		return c.RealIP()
+	}
+}
+
+func RateLimitByHeader(key string) RateLimitKeyFunc {
+	return func(c echo.Context, ec *Context) string {
+		return c.Request().Header.Get(key)
+	}
+}
+
+func RateLimitByCustomValue(key string) RateLimitKeyFunc {
+	return func(c echo.Context, ec *Context) string {
+		v, _ := ec.GetCustomValue(key)
+		return v
+	}
+}
+
+func RateLimitByHandler() RateLimitKeyFunc {
+	return func(c echo.Context, ec *Context) string {
+		return ec.GetHandlerName()
+	}
+}
+
+// RateLimit returns a handler which limits requests by limiter with the key of keyFunc.
+// Headers RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset are set for each request,
+// and the limited request is aborted with CodeTooManyRequests and header Retry-After.
+// The request is let through if the store of limiter fails.
+// The limiters sharing a store keep their keys apart, see ratelimit.WithName.
+func RateLimit(limiter ratelimit.Limiter, keyFunc RateLimitKeyFunc) HandlerFunc {
+	return func(c echo.Context, ec *Context) {
+		key := keyFunc(c, ec)
+		if key == "" {
+			return
+		}
+
+		result, err := limiter.Allow(ec, key)
+		if err != nil {
+			CtxWarn(ec, "rate limit %s failed: %v", key, err)
+			return
+		}
+
+		header := c.Response().Header()
+		header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+		header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		header.Set(HeaderRateLimitReset, formatSeconds(result.ResetAfter))
+
+		if !result.Allowed {
+			header.Set(HeaderRetryAfter, formatSeconds(result.RetryAfter))
+			ec.Abort(CodeTooManyRequests, ErrRateLimited)
+		}
+	}
+}
+
+// formatSeconds rounds d up to seconds.
+func formatSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
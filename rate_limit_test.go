@@ -0,0 +1,84 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.NewSlidingWindow(ratelimit.NewMemoryStore(), 2, time.Hour)
+	e := NewEngine()
+	handler := func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	}
+
+	perform := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		return PerformRequest(e, req, RateLimit(limiter, RateLimitByHeader("X-API-Key")), handler)
+	}
+
+	for i := 0; i < 2; i++ {
+		rec := perform("a")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get(HeaderRateLimitLimit))
+		assert.Empty(t, rec.Header().Get(HeaderRetryAfter))
+	}
+
+	rec := perform("a")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get(HeaderRateLimitRemaining))
+	assert.NotEmpty(t, rec.Header().Get(HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), `"code":42900`)
+
+	assert.Equal(t, http.StatusOK, perform("b").Code)
+	// no key, no limit.
+	assert.Equal(t, http.StatusOK, perform("").Code)
+}
+
+func TestRateLimit_SharedStore(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	strict := RateLimit(ratelimit.NewSlidingWindow(store, 1, time.Hour), RateLimitByIP())
+	loose := RateLimit(ratelimit.NewSlidingWindow(store, 10, time.Hour), RateLimitByIP())
+	named := RateLimit(ratelimit.NewSlidingWindow(store, 1, time.Hour, ratelimit.WithName("named")), RateLimitByIP())
+	e := NewEngine()
+	handler := func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	}
+
+	perform := func(limit HandlerFunc) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		return PerformRequest(e, req, limit, handler).Code
+	}
+
+	assert.Equal(t, http.StatusOK, perform(strict))
+	assert.Equal(t, http.StatusTooManyRequests, perform(strict))
+	// the limiters sharing the store do not share the buckets of the same key.
+	assert.Equal(t, http.StatusOK, perform(loose))
+	assert.Equal(t, http.StatusOK, perform(named))
+	assert.Equal(t, http.StatusTooManyRequests, perform(named))
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string) (*ratelimit.Result, error) {
+	return nil, errors.New("store down")
+}
+
+func TestRateLimit_FailOpen(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := PerformRequest(NewEngine(), req, RateLimit(failingLimiter{}, RateLimitByIP()), func(c echo.Context, ec *Context) {
+		ec.Finish(CodeOKZero, nil)
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(HeaderRateLimitLimit))
+}
@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	sweepInterval = time.Minute
+)
+
+type bucket struct {
+	tokens    float64
+	last      time.Time
+	expiresAt time.Time
+}
+
+type window struct {
+	start     time.Time
+	curr      int64
+	prev      int64
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the state in memory, which is only suitable for a single node.
+type MemoryStore struct {
+	lock      sync.Mutex
+	buckets   map[string]*bucket
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		windows: make(map[string]*window),
+	}
+}
+
+func (s *MemoryStore) TakeToken(ctx context.Context, key string, rate float64, burst int, now time.Time) (*Result, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sweep(now)
+
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{
+			tokens: float64(burst),
+			last:   now,
+		}
+		s.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+		b.last = now
+	}
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.expiresAt = now.Add(seconds(float64(burst) / rate))
+
+	return tokenResult(allowed, b.tokens, rate, burst), nil
+}
+
+func (s *MemoryStore) IncrWindow(ctx context.Context, key string, limit int, windowSize time.Duration, now time.Time) (*Result, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sweep(now)
+
+	start := now.Truncate(windowSize)
+	w, exists := s.windows[key]
+	switch {
+	case !exists:
+		w = &window{
+			start: start,
+		}
+		s.windows[key] = w
+	case start.Sub(w.start) == windowSize:
+		w.start, w.prev, w.curr = start, w.curr, 0
+	case start.Sub(w.start) > windowSize:
+		w.start, w.prev, w.curr = start, 0, 0
+	}
+
+	elapsed := now.Sub(start)
+	estimated := float64(w.prev)*float64(windowSize-elapsed)/float64(windowSize) + float64(w.curr)
+	allowed := estimated+1 <= float64(limit)
+	if allowed {
+		w.curr++
+	}
+	w.expiresAt = start.Add(windowSize * 2)
+
+	return windowResult(allowed, w.curr, w.prev, limit, windowSize, elapsed), nil
+}
+
+// sweep deletes the expired state at most once per sweepInterval.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.After(b.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if now.After(w.expiresAt) {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_TakeToken(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+
+	// 2 tokens per second, burst 3.
+	for i := 0; i < 3; i++ {
+		result, err := s.TakeToken(ctx, "k", 2, 3, start)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := s.TakeToken(ctx, "k", 2, 3, start)
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, result.ResetAfter)
+
+	result, err = s.TakeToken(ctx, "k", 2, 3, start.Add(500*time.Millisecond))
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = s.TakeToken(ctx, "other", 2, 3, start)
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryStore_IncrWindow(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0).Truncate(time.Minute)
+
+	for i := 0; i < 4; i++ {
+		result, err := s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*30))
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3-i, result.Remaining)
+	}
+
+	result, err := s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*30))
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second*30, result.RetryAfter)
+
+	// a quarter of the next window, the previous window weighs 3.
+	result, err = s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*75))
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, err = s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*75))
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	// the previous window must weigh 2 at most.
+	assert.Equal(t, time.Second*15, result.RetryAfter)
+
+	// the windows are reset after two windows.
+	result, err = s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*180))
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 3, result.Remaining)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+
+	_, _ = s.TakeToken(ctx, "k", 1, 1, start)
+	_, _ = s.IncrWindow(ctx, "k", 1, time.Second, start)
+	_, _ = s.TakeToken(ctx, "other", 1, 1, start.Add(time.Hour))
+
+	assert.Len(t, s.buckets, 1)
+	assert.Len(t, s.windows, 0)
+}
@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Result is the result of taking one request from a limiter.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is the duration until the limit is fully reset.
+	ResetAfter time.Duration
+	// RetryAfter is the duration until the next request can be allowed if it is not allowed.
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string) (*Result, error)
+}
+
+// Store keeps the state of limiters, it must be safe for concurrent use.
+type Store interface {
+	// TakeToken takes a token from the bucket of key which is refilled by rate tokens per second.
+	TakeToken(ctx context.Context, key string, rate float64, burst int, now time.Time) (*Result, error)
+	// IncrWindow counts a request in the sliding window of key if it is allowed.
+	IncrWindow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (*Result, error)
+}
+
+var now = time.Now
+
+type limiterConfig struct {
+	name string
+}
+
+type LimiterOption func(*limiterConfig)
+
+// WithName sets the namespace of the keys of the limiter in store.
+// By default it is built from the kind and the config of the limiter, so the limiters sharing
+// a store are kept apart unless they are configured alike.
+func WithName(name string) LimiterOption {
+	return func(cfg *limiterConfig) {
+		if name != "" {
+			cfg.name = name
+		}
+	}
+}
+
+// limiterName returns the prefix of the keys of the limiter.
+func limiterName(defaultName string, opts []LimiterOption) string {
+	cfg := &limiterConfig{name: defaultName}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg.name + ":"
+}
+
+type tokenBucket struct {
+	store Store
+	name  string
+	rate  float64
+	burst int
+}
+
+var _ Limiter = (*tokenBucket)(nil)
+
+// NewTokenBucket returns a limiter which allows burst requests at most,
+// and refills limit tokens per interval.
+func NewTokenBucket(store Store, limit int, interval time.Duration, burst int, opts ...LimiterOption) Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		store: store,
+		name:  limiterName(fmt.Sprintf("tb:%d/%s:%d", limit, interval, burst), opts),
+		rate:  float64(limit) / interval.Seconds(),
+		burst: burst,
+	}
+}
+
+func (l *tokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	return l.store.TakeToken(ctx, l.name+key, l.rate, l.burst, now())
+}
+
+type slidingWindow struct {
+	store  Store
+	name   string
+	limit  int
+	window time.Duration
+}
+
+var _ Limiter = (*slidingWindow)(nil)
+
+// NewSlidingWindow returns a limiter which allows limit requests in any window.
+// The count of the sliding window is estimated by the current and previous fixed windows.
+func NewSlidingWindow(store Store, limit int, window time.Duration, opts ...LimiterOption) Limiter {
+	return &slidingWindow{
+		store:  store,
+		name:   limiterName(fmt.Sprintf("sw:%d/%s", limit, window), opts),
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (l *slidingWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	return l.store.IncrWindow(ctx, l.name+key, l.limit, l.window, now())
+}
+
+// tokenResult builds the result by tokens left in the bucket.
+func tokenResult(allowed bool, tokens, rate float64, burst int) *Result {
+	result := &Result{
+		Allowed:    allowed,
+		Limit:      burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: seconds((float64(burst) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result
+}
+
+// windowResult builds the result by the counts of the current and previous windows.
+// curr contains the current request if it is allowed.
+func windowResult(allowed bool, curr, prev int64, limit int, window, elapsed time.Duration) *Result {
+	weight := float64(window-elapsed) / float64(window)
+	estimated := float64(prev)*weight + float64(curr)
+
+	result := &Result{
+		Allowed:    allowed,
+		Limit:      limit,
+		Remaining:  int(math.Max(0, math.Floor(float64(limit)-estimated))),
+		ResetAfter: window*2 - elapsed,
+	}
+	if !allowed {
+		if curr+1 > int64(limit) || prev == 0 {
+			result.RetryAfter = window - elapsed
+		} else {
+			// the weight of the previous window must drop to (limit-curr-1)/prev.
+			target := float64(int64(limit)-curr-1) / float64(prev)
+			result.RetryAfter = time.Duration((1-target)*float64(window)) - elapsed
+		}
+	}
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultRedisPrefix = "ratelimit:"
+)
+
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+	ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", ts)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000))
+return {allowed, tostring(tokens)}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local curr = tonumber(redis.call("GET", KEYS[1]) or "0")
+local prev = tonumber(redis.call("GET", KEYS[2]) or "0")
+if prev * (window - elapsed) / window + curr + 1 > limit then
+	return {0, curr, prev}
+end
+curr = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], window * 2)
+return {1, curr, prev}
+`)
+
+// RedisStore keeps the state in redis, which is shared by a fleet.
+// The keys of one limiter are hash tagged, so it works with redis cluster.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client redis.Scripter, prefixes ...string) *RedisStore {
+	prefix := DefaultRedisPrefix
+	if len(prefixes) > 0 {
+		prefix = prefixes[0]
+	}
+
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) TakeToken(ctx context.Context, key string, rate float64, burst int, now time.Time) (*Result, error) {
+	keys := []string{s.prefix + "{" + key + "}:tb"}
+	vals, err := tokenBucketScript.Run(ctx, s.client, keys, rate, burst, now.UnixMilli()).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := strconv.ParseFloat(vals[1].(string), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenResult(vals[0].(int64) == 1, tokens, rate, burst), nil
+}
+
+func (s *RedisStore) IncrWindow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (*Result, error) {
+	start := now.Truncate(window)
+	elapsed := now.Sub(start)
+	keys := []string{
+		s.prefix + "{" + key + "}:sw:" + strconv.FormatInt(start.UnixMilli(), 10),
+		s.prefix + "{" + key + "}:sw:" + strconv.FormatInt(start.Add(-window).UnixMilli(), 10),
+	}
+
+	vals, err := slidingWindowScript.Run(ctx, s.client, keys, limit, window.Milliseconds(), elapsed.Milliseconds()).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return windowResult(vals[0].(int64) == 1, vals[1].(int64), vals[2].(int64), limit, window, elapsed), nil
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return NewRedisStore(client), mr
+}
+
+func TestRedisStore_TakeToken(t *testing.T) {
+	s, mr := newTestRedisStore(t)
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+
+	// 2 tokens per second, burst 3.
+	for i := 0; i < 3; i++ {
+		result, err := s.TakeToken(ctx, "k", 2, 3, start)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+	}
+
+	result, err := s.TakeToken(ctx, "k", 2, 3, start)
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, result.ResetAfter)
+
+	result, err = s.TakeToken(ctx, "k", 2, 3, start.Add(500*time.Millisecond))
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = s.TakeToken(ctx, "other", 2, 3, start)
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+
+	assert.True(t, mr.Exists(DefaultRedisPrefix+"{k}:tb"))
+	assert.Equal(t, 1500*time.Millisecond, mr.TTL(DefaultRedisPrefix+"{k}:tb"))
+}
+
+func TestRedisStore_IncrWindow(t *testing.T) {
+	s, _ := newTestRedisStore(t)
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0).Truncate(time.Minute)
+
+	for i := 0; i < 4; i++ {
+		result, err := s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*30))
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3-i, result.Remaining)
+	}
+
+	result, err := s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*30))
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second*30, result.RetryAfter)
+
+	// a quarter of the next window, the previous window weighs 3.
+	result, err = s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*75))
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	result, err = s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*75))
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second*15, result.RetryAfter)
+
+	// the windows are reset after two windows.
+	result, err = s.IncrWindow(ctx, "k", 4, time.Minute, start.Add(time.Second*180))
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 3, result.Remaining)
+}
+
+func TestRedisStore_Limiters(t *testing.T) {
+	s, mr := newTestRedisStore(t)
+	ctx := context.Background()
+	strict := NewTokenBucket(s, 1, time.Hour, 1)
+	loose := NewTokenBucket(s, 10, time.Hour, 10)
+
+	result, err := strict.Allow(ctx, "ip")
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = strict.Allow(ctx, "ip")
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+
+	result, err = loose.Allow(ctx, "ip")
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Len(t, mr.Keys(), 2)
+
+	mr.Close()
+	_, err = strict.Allow(ctx, "ip")
+	assert.NotNil(t, err)
+}
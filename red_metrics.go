@@ -0,0 +1,110 @@
+package echotool
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/metric"
+)
+
+const (
+	MRequests         = "requests_total"
+	MRequestsInFlight = "requests_in_flight"
+	MRequestDuration  = "request_duration_seconds"
+)
+
+// WithREDMetrics makes the engine record the rate, errors and duration of each handler by c,
+// which is metric.DefaultMetricClient if c is nil. The metrics are defined by the option,
+// and labeled by handler name, http status and the class of business code.
+func WithREDMetrics(c *metric.MetricClient) Option {
+	return func(e *Engine) {
+		if c == nil {
+			c = metric.DefaultMetricClient
+		}
+
+		if err := DefineREDMetrics(c); err != nil {
+			Error("define red metrics error: %v", err)
+			return
+		}
+
+		e.metricClient = c
+	}
+}
+
+// DefineREDMetrics defines the metrics recorded by WithREDMetrics in c if they are not defined.
+func DefineREDMetrics(c *metric.MetricClient) error {
+	labels, flightLabels := newREDLabels("", 0, 0), newFlightLabels("")
+
+	if !c.IsDefined(MRequests) {
+		if err := c.DefineCounter(MRequests, labels); err != nil {
+			return err
+		}
+	}
+	if !c.IsDefined(MRequestsInFlight) {
+		if err := c.DefineGauge(MRequestsInFlight, flightLabels); err != nil {
+			return err
+		}
+	}
+	if !c.IsDefined(MRequestDuration) {
+		if err := c.DefineHistogram(MRequestDuration, labels, metric.DefaultBuckets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) startMetrics(ec *Context) {
+	_ = e.metricClient.AddGauge(MRequestsInFlight, 1, newFlightLabels(ec.GetHandlerName()))
+}
+
+func (e *Engine) finishMetrics(c echo.Context, ec *Context) {
+	handler := ec.GetHandlerName()
+	_ = e.metricClient.AddGauge(MRequestsInFlight, -1, newFlightLabels(handler))
+
+	labels := newREDLabels(handler, c.Response().Status, ec.GetCode())
+	_ = e.metricClient.EmitCounter(MRequests, 1, labels)
+	_ = e.metricClient.EmitHistogram(MRequestDuration, time.Since(ec.GetStartTime()).Seconds(), labels)
+}
+
+type redLabels struct {
+	Handler   string
+	Status    int
+	CodeClass string
+}
+
+var _ metric.LabelsParser = (*redLabels)(nil)
+
+func newREDLabels(handler string, status, code int) *redLabels {
+	return &redLabels{
+		Handler:   handler,
+		Status:    status,
+		CodeClass: CodeClass(code),
+	}
+}
+
+func (ls *redLabels) ParseToLabels() map[string]string {
+	return map[string]string{
+		"handler":    ls.Handler,
+		"status":     strconv.Itoa(ls.Status),
+		"code_class": ls.CodeClass,
+	}
+}
+
+type flightLabels struct {
+	Handler string
+}
+
+var _ metric.LabelsParser = (*flightLabels)(nil)
+
+func newFlightLabels(handler string) *flightLabels {
+	return &flightLabels{
+		Handler: handler,
+	}
+}
+
+func (ls *flightLabels) ParseToLabels() map[string]string {
+	return map[string]string{
+		"handler": ls.Handler,
+	}
+}
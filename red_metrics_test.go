@@ -0,0 +1,57 @@
+package echotool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/songzhaoliang/echotool/metric"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_WithREDMetrics(t *testing.T) {
+	c := metric.NewMetricClient(metric.WithNamespace("red_test"))
+	e := NewEngine(WithREDMetrics(c))
+	// the metrics are defined only once.
+	e = e.Group(WithREDMetrics(c))
+
+	var (
+		name     string
+		inFlight float64
+	)
+	handler := func(c echo.Context, ec *Context) {
+		name = ec.GetHandlerName()
+		inFlight = gaugeValue(t, e, name)
+		ec.Abort(CodeNotFound, nil)
+	}
+
+	for i := 0; i < 2; i++ {
+		PerformRequest(e, httptest.NewRequest(http.MethodGet, "/", nil), handler)
+	}
+
+	assert.Equal(t, float64(1), inFlight)
+	assert.Equal(t, float64(0), gaugeValue(t, e, name))
+
+	v, _ := c.AllMetrics.Load(MRequests)
+	counter := v.(*prometheus.CounterVec).With(prometheus.Labels{"handler": name, "status": "404", "code_class": "4xxxx"})
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+
+	v, _ = c.AllMetrics.Load(MRequestDuration)
+	assert.Equal(t, 1, testutil.CollectAndCount(v.(*prometheus.HistogramVec)))
+}
+
+func TestCodeClass(t *testing.T) {
+	assert.Equal(t, "0", CodeClass(CodeOKZero))
+	assert.Equal(t, "2xxxx", CodeClass(CodeOK))
+	assert.Equal(t, "5xxxx", CodeClass(CodeDownstreamErr))
+	assert.Equal(t, "other", CodeClass(-1))
+}
+
+func gaugeValue(t *testing.T, e *Engine, handler string) float64 {
+	v, exists := e.metricClient.AllMetrics.Load(MRequestsInFlight)
+	assert.True(t, exists)
+	return testutil.ToFloat64(v.(*prometheus.GaugeVec).WithLabelValues(handler))
+}
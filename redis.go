@@ -2,9 +2,11 @@ package echotool
 
 import (
 	"context"
+	"fmt"
 	"net"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/songzhaoliang/echotool/trace"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -27,29 +29,95 @@ func NewRedisLogger(level string) *RedisLogger {
 
 func (l *RedisLogger) DialHook(next redis.DialHook) redis.DialHook {
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
-		ec, _ := ctx.(*Context)
+		ec := FromContext(ctx)
 		CtxPrint(ec, l.Level, "[redis] dial network %s, addr %s", network, addr)
 
-		return next(ctx, network, addr)
+		ctx, span := trace.Start(ctx, "redis dial",
+			trace.WithKind(trace.KindClient),
+			trace.WithAttribute("net.peer.addr", addr))
+		conn, err := next(ctx, network, addr)
+		span.RecordError(err)
+		span.End()
+		return conn, err
 	}
 }
 
 func (l *RedisLogger) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
-		ec, _ := ctx.(*Context)
+		ec := FromContext(ctx)
 		CtxPrint(ec, l.Level, "[redis] %s", cmd.String())
 
-		return next(ctx, cmd)
+		ctx, span := trace.Start(ctx, "redis "+cmd.Name(),
+			trace.WithKind(trace.KindClient),
+			trace.WithAttribute("db.system", "redis"),
+			trace.WithAttribute("db.statement", redisStatement(cmd)))
+		err := next(ctx, cmd)
+		if err != redis.Nil {
+			span.RecordError(err)
+		}
+		span.End()
+		return err
 	}
 }
 
 func (l *RedisLogger) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
-		ec, _ := ctx.(*Context)
+		ec := FromContext(ctx)
 		for _, cmd := range cmds {
 			CtxPrint(ec, l.Level, "[redis] %s", cmd.String())
 		}
 
-		return next(ctx, cmds)
+		ctx, span := trace.Start(ctx, "redis pipeline",
+			trace.WithKind(trace.KindClient),
+			trace.WithAttribute("db.system", "redis"),
+			trace.WithAttribute("db.redis.commands", len(cmds)))
+		err := next(ctx, cmds)
+		if err != redis.Nil {
+			span.RecordError(err)
+		}
+		span.End()
+		return err
+	}
+}
+
+// redisKeylessCommands are the commands whose first argument is not a key, e.g. the password of auth.
+var redisKeylessCommands = map[string]bool{
+	"acl":      true,
+	"auth":     true,
+	"client":   true,
+	"cluster":  true,
+	"command":  true,
+	"config":   true,
+	"echo":     true,
+	"function": true,
+	"hello":    true,
+	"info":     true,
+	"ping":     true,
+	"script":   true,
+	"select":   true,
+}
+
+// redisStatement returns the name and the key of cmd for spans.
+// The other arguments are left out since they may carry sensitive values.
+func redisStatement(cmd redis.Cmder) string {
+	name, args := cmd.FullName(), cmd.Args()
+
+	pos := 1
+	switch cmd.Name() {
+	case "eval", "evalsha", "eval_ro", "evalsha_ro", "fcall", "fcall_ro":
+		// the script and the number of keys go before the keys.
+		if len(args) < 4 || fmt.Sprint(args[2]) == "0" {
+			return name
+		}
+		pos = 3
+	default:
+		if redisKeylessCommands[cmd.Name()] {
+			return name
+		}
+	}
+
+	if pos >= len(args) {
+		return name
 	}
+	return fmt.Sprintf("%s %v", name, args[pos])
 }
@@ -0,0 +1,165 @@
+package echotool
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/protos"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/types/known/anypb"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	ErrNotProtoMessage = errors.New("not proto message")
+)
+
+// offers are the media types which can be rendered, json is the first one as the fallback.
+var offers = []string{
+	echo.MIMEApplicationJSON,
+	echo.MIMEApplicationXML,
+	echo.MIMEApplicationProtobuf,
+	echo.MIMEApplicationMsgpack,
+	MIMEApplicationYAML,
+}
+
+var aliases = map[string]string{
+	echo.MIMEApplicationJSON:     echo.MIMEApplicationJSON,
+	echo.MIMEApplicationXML:      echo.MIMEApplicationXML,
+	echo.MIMETextXML:             echo.MIMEApplicationXML,
+	echo.MIMEApplicationProtobuf: echo.MIMEApplicationProtobuf,
+	MIMEApplicationXProtobuf:     echo.MIMEApplicationProtobuf,
+	echo.MIMEApplicationMsgpack:  echo.MIMEApplicationMsgpack,
+	MIMEApplicationXMsgpack:      echo.MIMEApplicationMsgpack,
+	MIMEApplicationYAML:          MIMEApplicationYAML,
+	MIMEApplicationXYAML:         MIMEApplicationYAML,
+	MIMETextYAML:                 MIMEApplicationYAML,
+}
+
+type acceptItem struct {
+	mediaType string
+	quality   float64
+}
+
+// Negotiate returns the best media type in offers for header Accept.
+// The result is json if nothing matches.
+func Negotiate(accept string) string {
+	if accept == "" {
+		return offers[0]
+	}
+
+	var items []acceptItem
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			items = append(items, acceptItem{mediaType, quality})
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].quality > items[j].quality
+	})
+
+	for _, item := range items {
+		if offer, exists := aliases[item.mediaType]; exists {
+			return offer
+		}
+		if item.mediaType == "*/*" || item.mediaType == "application/*" {
+			return offers[0]
+		}
+	}
+	return offers[0]
+}
+
+// Render writes resp in the media type negotiated by header Accept, so header Vary has Accept.
+// It falls back to json if resp can not be encoded in the negotiated one,
+// e.g. protobuf is only used when the data is nil or proto.Message.
+func Render(c echo.Context, status int, resp *CommonResponse) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	varyAccept(c.Response().Header())
+
+	contentType := Negotiate(c.Request().Header.Get(echo.HeaderAccept))
+	switch contentType {
+	case echo.MIMEApplicationXML:
+		b, err = xml.Marshal(resp)
+		contentType = echo.MIMEApplicationXMLCharsetUTF8
+	case echo.MIMEApplicationProtobuf:
+		b, err = marshalProtobuf(resp)
+	case echo.MIMEApplicationMsgpack:
+		b, err = marshalMsgpack(resp)
+	case MIMEApplicationYAML:
+		b, err = yaml.Marshal(resp)
+	default:
+		return c.JSON(status, resp)
+	}
+
+	if err != nil {
+		return c.JSON(status, resp)
+	}
+	return c.Blob(status, contentType, b)
+}
+
+// varyAccept adds Accept to header Vary once, so that caches keep the representations apart.
+func varyAccept(header http.Header) {
+	for _, v := range header.Values(echo.HeaderVary) {
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); field == "*" || strings.EqualFold(field, echo.HeaderAccept) {
+				return
+			}
+		}
+	}
+	header.Add(echo.HeaderVary, echo.HeaderAccept)
+}
+
+func marshalProtobuf(resp *CommonResponse) ([]byte, error) {
+	pr := &protos.CommonResponse{
+		RequestId: resp.RequestID,
+		Code:      int32(resp.Code),
+		Message:   resp.Message,
+	}
+
+	if resp.Data != nil {
+		msg, ok := resp.Data.(proto.Message)
+		if !ok {
+			return nil, ErrNotProtoMessage
+		}
+
+		data, err := anypb.New(proto.MessageV2(msg))
+		if err != nil {
+			return nil, err
+		}
+		pr.Data = data
+	}
+
+	return proto.Marshal(pr)
+}
+
+func marshalMsgpack(resp *CommonResponse) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := codec.NewEncoder(&buffer, &codec.MsgpackHandle{}).Encode(resp); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
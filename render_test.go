@@ -0,0 +1,118 @@
+package echotool
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/protos"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gopkg.in/yaml.v2"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                                     echo.MIMEApplicationJSON,
+		"*/*":                                  echo.MIMEApplicationJSON,
+		"text/html":                            echo.MIMEApplicationJSON,
+		"text/xml":                             echo.MIMEApplicationXML,
+		"application/x-protobuf":               echo.MIMEApplicationProtobuf,
+		"application/json;q=0.5, text/yaml":    MIMEApplicationYAML,
+		"application/msgpack, application/xml": echo.MIMEApplicationMsgpack,
+		"application/xml;q=0, */*;q=0.1":       echo.MIMEApplicationJSON,
+	}
+
+	for accept, expected := range cases {
+		assert.Equal(t, expected, Negotiate(accept), accept)
+	}
+}
+
+func TestRender_XML(t *testing.T) {
+	rec := performRender(echo.MIMEApplicationXML, CodeOK, &User{ID: 1, Name: "peter"})
+	assert.Equal(t, echo.MIMEApplicationXMLCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+
+	resp := &struct {
+		Code int    `xml:"code"`
+		Name string `xml:"data>Name"`
+	}{}
+	assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Equal(t, CodeOK, resp.Code)
+	assert.Equal(t, "peter", resp.Name)
+}
+
+func TestRender_Vary(t *testing.T) {
+	rec := performRender("", CodeOK, nil)
+	assert.Equal(t, []string{echo.HeaderAccept}, rec.Header().Values(echo.HeaderVary))
+
+	header := http.Header{}
+	header.Set(echo.HeaderVary, "Accept-Encoding, accept")
+	varyAccept(header)
+	assert.Equal(t, []string{"Accept-Encoding, accept"}, header.Values(echo.HeaderVary))
+}
+
+func TestRender_YAML(t *testing.T) {
+	rec := performRender(MIMETextYAML, CodeOK, nil)
+
+	resp := &CommonResponse{}
+	assert.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Equal(t, CodeOK, resp.Code)
+	assert.Equal(t, CodeMsg(CodeOK), resp.Message)
+}
+
+func TestRender_Msgpack(t *testing.T) {
+	rec := performRender(echo.MIMEApplicationMsgpack, CodeOK, "data")
+
+	h := &codec.MsgpackHandle{}
+	h.RawToString = true
+
+	resp := &CommonResponse{}
+	assert.NoError(t, codec.NewDecoderBytes(rec.Body.Bytes(), h).Decode(resp))
+	assert.Equal(t, CodeOK, resp.Code)
+	assert.Equal(t, "data", resp.Data)
+}
+
+func TestRender_Protobuf(t *testing.T) {
+	rec := performRender(echo.MIMEApplicationProtobuf, CodeOK, wrapperspb.String("data"))
+	assert.Equal(t, echo.MIMEApplicationProtobuf, rec.Header().Get(echo.HeaderContentType))
+
+	resp := &protos.CommonResponse{}
+	assert.NoError(t, proto.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Equal(t, int32(CodeOK), resp.Code)
+
+	data := &wrapperspb.StringValue{}
+	assert.NoError(t, resp.Data.UnmarshalTo(data))
+	assert.Equal(t, "data", data.Value)
+
+	// data which is not proto.Message falls back to json.
+	rec = performRender(echo.MIMEApplicationProtobuf, CodeOK, "data")
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+}
+
+func TestRender_Aborter(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, MIMEApplicationXYAML)
+
+	rec := PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		ec.Abort(CodeNotFound, errors.New("no user"))
+	})
+
+	resp := &CommonResponse{}
+	assert.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), resp))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "not found - no user", resp.Message)
+}
+
+func performRender(accept string, code int, data interface{}) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, accept)
+
+	return PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		ec.Finish(code, data)
+	})
+}
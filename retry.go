@@ -0,0 +1,134 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryConfig is the config of Retry.
+type RetryConfig struct {
+	// Attempts is the maximal number of calls including the first one.
+	Attempts int
+	// Backoff is the wait before the first retry, it is multiplied by Multiplier for each retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Multiplier float64
+	// Jitter randomly cuts the ratio of each wait, in [0, 1].
+	Jitter float64
+	// Retryable reports whether err should be retried,
+	// all errors except ErrBreakerOpen are by default.
+	Retryable func(error) bool
+	// Context stops the retries once it is done.
+	Context context.Context
+}
+
+type RetryOption func(*RetryConfig)
+
+func WithRetryAttempts(attempts int) RetryOption {
+	return func(cfg *RetryConfig) {
+		if attempts > 0 {
+			cfg.Attempts = attempts
+		}
+	}
+}
+
+func WithRetryBackoff(backoff, maxBackoff time.Duration) RetryOption {
+	return func(cfg *RetryConfig) {
+		if backoff > 0 {
+			cfg.Backoff = backoff
+		}
+		if maxBackoff >= backoff {
+			cfg.MaxBackoff = maxBackoff
+		}
+	}
+}
+
+func WithRetryMultiplier(multiplier float64) RetryOption {
+	return func(cfg *RetryConfig) {
+		if multiplier >= 1 {
+			cfg.Multiplier = multiplier
+		}
+	}
+}
+
+func WithRetryJitter(jitter float64) RetryOption {
+	return func(cfg *RetryConfig) {
+		if jitter >= 0 && jitter <= 1 {
+			cfg.Jitter = jitter
+		}
+	}
+}
+
+func WithRetryable(retryable func(error) bool) RetryOption {
+	return func(cfg *RetryConfig) {
+		if retryable != nil {
+			cfg.Retryable = retryable
+		}
+	}
+}
+
+// WithRetryContext stops the retries once ctx is done, e.g. the request is canceled.
+func WithRetryContext(ctx context.Context) RetryOption {
+	return func(cfg *RetryConfig) {
+		if ctx != nil {
+			cfg.Context = ctx
+		}
+	}
+}
+
+// Retry returns a RunFunc which calls run until it succeeds, with exponential backoff and jitter.
+// The last error is returned if all attempts fail, and it can be passed to MustDo as well, e.g.
+//
+//	MustDo(Retry(GetBreaker("user").Wrap(run), WithRetryContext(ec)))
+func Retry(run RunFunc, opts ...RetryOption) RunFunc {
+	cfg := &RetryConfig{
+		Attempts:   3,
+		Backoff:    time.Millisecond * 100,
+		MaxBackoff: time.Second * 2,
+		Multiplier: 2,
+		Jitter:     0.2,
+		Retryable: func(err error) bool {
+			return !errors.Is(err, ErrBreakerOpen)
+		},
+		Context: context.Background(),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func() (result interface{}, err error) {
+		backoff := cfg.Backoff
+		for attempt := 1; ; attempt++ {
+			if result, err = run(); err == nil {
+				return
+			}
+			if attempt >= cfg.Attempts || !cfg.Retryable(err) {
+				return
+			}
+
+			wait := backoff - time.Duration(float64(backoff)*cfg.Jitter*rand.Float64())
+			if !sleep(cfg.Context, wait) {
+				return
+			}
+
+			if backoff = time.Duration(float64(backoff) * cfg.Multiplier); backoff > cfg.MaxBackoff {
+				backoff = cfg.MaxBackoff
+			}
+		}
+	}
+}
+
+// sleep waits for d, it returns false if ctx is done before.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	run := Retry(func() (interface{}, error) {
+		if calls++; calls < 3 {
+			return nil, errDownstream
+		}
+		return calls, nil
+	}, WithRetryAttempts(3), WithRetryBackoff(time.Millisecond, time.Millisecond*2))
+
+	result, err := run()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result)
+}
+
+func TestRetry_Exhausted(t *testing.T) {
+	calls := 0
+	_, err := Retry(func() (interface{}, error) {
+		calls++
+		return nil, errDownstream
+	}, WithRetryAttempts(2), WithRetryBackoff(time.Millisecond, time.Millisecond))()
+
+	assert.Equal(t, errDownstream, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_BreakerOpen(t *testing.T) {
+	b := NewBreaker("retry", WithBreakerMinRequests(1), WithBreakerCoolDown(time.Hour))
+
+	calls := 0
+	_, err := Retry(b.Wrap(func() (interface{}, error) {
+		calls++
+		return nil, errDownstream
+	}), WithRetryAttempts(5), WithRetryBackoff(time.Millisecond, time.Millisecond))()
+
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetry_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	_, err := Retry(func() (interface{}, error) {
+		calls++
+		return nil, errDownstream
+	}, WithRetryContext(ctx), WithRetryBackoff(time.Hour, time.Hour))()
+
+	assert.Equal(t, errDownstream, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,256 @@
+package echotool
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/json"
+	"github.com/songzhaoliang/echotool/util"
+)
+
+const (
+	DefaultRoutesPrefix = "/debug/routes"
+)
+
+// RouteInfo is the metadata of a route added by Engine.Add or AddHandle.
+// The handlers of EchoHandler and Handle added to echo directly are not recorded.
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`
+	BindFlags   []string `json:"bind_flags,omitempty"`
+
+	// typed is true if the handler is registered by Handle, which checks the attribute rules.
+	typed bool
+}
+
+func (ri *RouteInfo) key() string {
+	return ri.Method + " " + ri.Path
+}
+
+// isBound reports whether the method and path of the route are known.
+func (ri *RouteInfo) isBound() bool {
+	return ri.Method != "" || ri.Path != ""
+}
+
+func (ri *RouteInfo) equal(other *RouteInfo) bool {
+	return ri.Handler == other.Handler &&
+		equalStrings(ri.Middlewares, other.Middlewares) &&
+		equalStrings(ri.BindFlags, other.BindFlags)
+}
+
+var (
+	routesLock sync.RWMutex
+	routes     []*RouteInfo
+)
+
+// Routes returns the routes added by Engine.Add and AddHandle of all engines, sorted by path and method.
+func Routes() []*RouteInfo {
+	routesLock.RLock()
+	defer routesLock.RUnlock()
+
+	infos := make([]*RouteInfo, 0, len(routes))
+	for _, ri := range routes {
+		infos = append(infos, &RouteInfo{
+			Method:      ri.Method,
+			Path:        ri.Path,
+			Handler:     ri.Handler,
+			Middlewares: ri.Middlewares,
+			BindFlags:   ri.BindFlags,
+		})
+	}
+	sortRoutes(infos)
+	return infos
+}
+
+// LoadRoutes reads the routes saved from Routes or the endpoint, e.g. those of the last build.
+func LoadRoutes(path string) ([]*RouteInfo, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*RouteInfo
+	if err = json.Unmarshal(b, &infos); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// RouteChange is a route whose handler, middlewares or bind flags are changed.
+type RouteChange struct {
+	Old *RouteInfo `json:"old"`
+	New *RouteInfo `json:"new"`
+}
+
+type RouteDiff struct {
+	Added   []*RouteInfo   `json:"added"`
+	Removed []*RouteInfo   `json:"removed"`
+	Changed []*RouteChange `json:"changed"`
+}
+
+func (d *RouteDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffRoutes compares the routes by method and path, so that the routes removed by accident are caught.
+// The routes without method and path, which are saved by the old versions, are left out.
+func DiffRoutes(old, new []*RouteInfo) *RouteDiff {
+	old, new = boundRoutes(old), boundRoutes(new)
+	olds := make(map[string]*RouteInfo, len(old))
+	for _, ri := range old {
+		olds[ri.key()] = ri
+	}
+
+	d := &RouteDiff{
+		Added:   []*RouteInfo{},
+		Removed: []*RouteInfo{},
+		Changed: []*RouteChange{},
+	}
+	news := make(map[string]struct{}, len(new))
+	for _, ri := range new {
+		key := ri.key()
+		news[key] = struct{}{}
+
+		if o, exists := olds[key]; !exists {
+			d.Added = append(d.Added, ri)
+		} else if !o.equal(ri) {
+			d.Changed = append(d.Changed, &RouteChange{
+				Old: o,
+				New: ri,
+			})
+		}
+	}
+	for _, ri := range old {
+		if _, exists := news[ri.key()]; !exists {
+			d.Removed = append(d.Removed, ri)
+		}
+	}
+
+	sortRoutes(d.Added)
+	sortRoutes(d.Removed)
+	sort.SliceStable(d.Changed, func(i, j int) bool {
+		return d.Changed[i].New.key() < d.Changed[j].New.key()
+	})
+	return d
+}
+
+// RegisterRoutes mounts GET /debug/routes which responds the routes,
+// and POST /debug/routes/diff which responds the diff from the routes in body to the current ones.
+// They have no authentication, so they should be mounted by RouterRegisterRoutes with an authenticated group
+// unless r is only reachable internally.
+func RegisterRoutes(r *echo.Echo, prefixes ...string) {
+	registerRoutes(r.Group(util.GetPrefix(append(prefixes, DefaultRoutesPrefix)...)))
+}
+
+func RouterRegisterRoutes(g *echo.Group, prefixes ...string) {
+	registerRoutes(g.Group(util.GetPrefix(append(prefixes, DefaultRoutesPrefix)...)))
+}
+
+func registerRoutes(g *echo.Group) {
+	g.GET("", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, Routes())
+	})
+	g.POST("/diff", func(c echo.Context) error {
+		var old []*RouteInfo
+		if err := json.NewDecoder(c.Request().Body).Decode(&old); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return c.JSON(http.StatusOK, DiffRoutes(old, Routes()))
+	})
+}
+
+// Router is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	Add(method, path string, handler echo.HandlerFunc, middleware ...echo.MiddlewareFunc) *echo.Route
+}
+
+var _ Router = (*echo.Echo)(nil)
+var _ Router = (*echo.Group)(nil)
+
+// Add adds the handler built by EchoHandler to r, and records the route with its method and path.
+// It should be used instead of adding the handler to echo directly, so that DiffRoutes works.
+func (e *Engine) Add(r Router, method, path string, handlers ...HandlerFunc) *echo.Route {
+	if len(handlers) == 0 {
+		return r.Add(method, path, e.EchoHandler())
+	}
+
+	return e.add(r, method, path, &RouteInfo{Handler: GetHandlerName(handlers[0])}, handlers...)
+}
+
+func (e *Engine) add(r Router, method, path string, ri *RouteInfo, handlers ...HandlerFunc) *echo.Route {
+	route := r.Add(method, path, e.echoHandler(ri, handlers...))
+	route.Name = ri.Handler
+	// the full path is known after the route is added to r, e.g. with the prefix of the group.
+	ri.Method, ri.Path = route.Method, route.Path
+	recordRoute(ri)
+	return route
+}
+
+func recordRoute(ri *RouteInfo) {
+	routesLock.Lock()
+	defer routesLock.Unlock()
+	routes = append(routes, ri)
+}
+
+// middlewareNames returns the names of the middlewares in chain for RouteInfo.
+func middlewareNames(chain HandlerFuncsChain) []string {
+	names := make([]string, 0, len(chain))
+	for _, h := range chain {
+		names = append(names, getMiddlewareName(h))
+	}
+	return names
+}
+
+func boundRoutes(infos []*RouteInfo) []*RouteInfo {
+	bound := make([]*RouteInfo, 0, len(infos))
+	for _, ri := range infos {
+		if ri.isBound() {
+			bound = append(bound, ri)
+		}
+	}
+	return bound
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// getMiddlewareName returns the name of f with its package, e.g. "echotool.AddTraceID"
+// for the middleware returned by AddTraceID.
+func getMiddlewareName(f HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+	return closureSuffix.ReplaceAllString(name, "")
+}
+
+func sortRoutes(infos []*RouteInfo) {
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+		if infos[i].Method != infos[j].Method {
+			return infos[i].Method < infos[j].Method
+		}
+		return infos[i].Handler < infos[j].Handler
+	})
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package echotool
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func resetRoutes(t *testing.T) {
+	routesLock.Lock()
+	origin := routes
+	routes = nil
+	routesLock.Unlock()
+	t.Cleanup(func() {
+		routesLock.Lock()
+		routes = origin
+		routesLock.Unlock()
+	})
+}
+
+type routeUserReq struct {
+	ID    int64  `param:"id"`
+	Token string `header:"X-Token"`
+	Name  string `json:"name" valid:"required"`
+}
+
+func routeGetUser(ec *Context, req *routeUserReq) (*routeUserReq, error) {
+	return req, nil
+}
+
+func routeListUsers(c echo.Context, ec *Context) {
+	ec.Finish(CodeOK, nil)
+}
+
+func TestEngine_Add(t *testing.T) {
+	resetRoutes(t)
+
+	r := echo.New()
+	e := NewEngine(WithMiddlewares(AddTraceID(GetRequestID)))
+	route := e.Add(r.Group("/api"), http.MethodGet, "/users", routeListUsers)
+	assert.Equal(t, "/api/users", route.Path)
+	assert.Equal(t, "routeListUsers", route.Name)
+
+	route = AddHandle(e, r, http.MethodPut, "/users/:id", routeGetUser)
+	assert.Equal(t, "routeGetUser", route.Name)
+	e.Group(WithMiddlewares(PrintRequest())).Add(r, http.MethodDelete, "/users/:id", routeListUsers)
+
+	// the handlers built but not added by the engine are not recorded.
+	r.GET("/a", e.EchoHandler(routeListUsers))
+	Handle(e, routeGetUser)
+	// the handler added twice is recorded for each route.
+	e.Add(r, http.MethodGet, "/b", routeListUsers)
+
+	infos := Routes()
+	assert.Len(t, infos, 4)
+	assert.Equal(t, &RouteInfo{
+		Method:      http.MethodGet,
+		Path:        "/api/users",
+		Handler:     "routeListUsers",
+		Middlewares: []string{"echotool.AddTraceID"},
+	}, infos[0])
+	assert.Equal(t, &RouteInfo{
+		Method:      http.MethodGet,
+		Path:        "/b",
+		Handler:     "routeListUsers",
+		Middlewares: []string{"echotool.AddTraceID"},
+	}, infos[1])
+	assert.Equal(t, &RouteInfo{
+		Method:      http.MethodDelete,
+		Path:        "/users/:id",
+		Handler:     "routeListUsers",
+		Middlewares: []string{"echotool.AddTraceID", "echotool.PrintRequest"},
+	}, infos[2])
+	assert.Equal(t, &RouteInfo{
+		Method:      http.MethodPut,
+		Path:        "/users/:id",
+		Handler:     "routeGetUser",
+		Middlewares: []string{"echotool.AddTraceID"},
+		BindFlags:   []string{"validator", "header", "param", "json", "xml", "msgpack", "yaml"},
+	}, infos[3])
+
+	req := httptest.NewRequest(http.MethodPut, "/users/1", bytes.NewBufferString(`{"name":"peter"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestDiffRoutes(t *testing.T) {
+	old := []*RouteInfo{
+		{Method: http.MethodGet, Path: "/users", Handler: "ListUsers"},
+		{Method: http.MethodDelete, Path: "/users/:id", Handler: "DeleteUser"},
+		{Method: http.MethodPost, Path: "/users", Handler: "CreateUser", Middlewares: []string{"main.CheckAdmin"}},
+	}
+	new := []*RouteInfo{
+		{Method: http.MethodGet, Path: "/users", Handler: "ListUsers"},
+		{Method: http.MethodPost, Path: "/users", Handler: "CreateUser"},
+		{Method: http.MethodGet, Path: "/users/:id", Handler: "GetUser"},
+	}
+
+	d := DiffRoutes(old, new)
+	assert.False(t, d.IsEmpty())
+	assert.Equal(t, []*RouteInfo{new[2]}, d.Added)
+	assert.Equal(t, []*RouteInfo{old[1]}, d.Removed)
+	assert.Equal(t, []*RouteChange{{Old: old[2], New: new[1]}}, d.Changed)
+
+	assert.True(t, DiffRoutes(new, new).IsEmpty())
+
+	// the routes without method and path are left out.
+	unbound := []*RouteInfo{{Handler: "ListUsers"}, {Handler: "GetUser"}}
+	assert.True(t, DiffRoutes(new, append(unbound, new...)).IsEmpty())
+	d = DiffRoutes(unbound, new[:1])
+	assert.Equal(t, []*RouteInfo{new[0]}, d.Added)
+	assert.Len(t, d.Removed, 0)
+}
+
+func TestRegisterRoutes(t *testing.T) {
+	resetRoutes(t)
+
+	r := echo.New()
+	RegisterRoutes(r)
+	e := NewEngine()
+	e.Add(r, http.MethodGet, "/users", routeListUsers)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultRoutesPrefix, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	path := filepath.Join(t.TempDir(), "routes.json")
+	assert.Nil(t, os.WriteFile(path, rec.Body.Bytes(), 0644))
+	old, err := LoadRoutes(path)
+	assert.Nil(t, err)
+	assert.Len(t, old, 1)
+
+	old = append(old, &RouteInfo{Method: http.MethodDelete, Path: "/users/:id", Handler: "DeleteUser"})
+	b, _ := json.Marshal(old)
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, DefaultRoutesPrefix+"/diff", bytes.NewReader(b)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	d := &RouteDiff{}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), d))
+	assert.Len(t, d.Added, 0)
+	assert.Len(t, d.Changed, 0)
+	assert.Equal(t, []*RouteInfo{{Method: http.MethodDelete, Path: "/users/:id", Handler: "DeleteUser"}}, d.Removed)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, DefaultRoutesPrefix+"/diff", bytes.NewBufferString("{")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,282 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/health"
+)
+
+const (
+	DefaultAddress      = ":1323"
+	DefaultDrainTimeout = time.Second * 30
+	DefaultStartTimeout = time.Minute
+
+	// ServerCheckName is the name of the readiness check of Server in package health.
+	ServerCheckName = "server"
+)
+
+var (
+	ErrServerNotReady = errors.New("server is not ready")
+)
+
+// HookFunc is a hook of Server, it should return once ctx is done.
+type HookFunc func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   HookFunc
+}
+
+// Server runs an echo instance with lifecycle hooks, and shuts it down gracefully by signals.
+type Server struct {
+	echo         *echo.Echo
+	address      string
+	drainTimeout time.Duration
+	startTimeout time.Duration
+	signals      []os.Signal
+
+	startHooks []hook
+	stopHooks  []hook
+
+	ready    int32
+	listener net.Listener
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type ServerOption func(*Server)
+
+func WithServerEcho(r *echo.Echo) ServerOption {
+	return func(s *Server) {
+		if r != nil {
+			s.echo = r
+		}
+	}
+}
+
+func WithServerAddress(address string) ServerOption {
+	return func(s *Server) {
+		if address != "" {
+			s.address = address
+		}
+	}
+}
+
+// WithDrainTimeout sets the timeout of draining the requests in flight, which is also the timeout of the stop hooks.
+func WithDrainTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.drainTimeout = timeout
+		}
+	}
+}
+
+// WithStartTimeout sets the timeout of each start hook.
+func WithStartTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		if timeout > 0 {
+			s.startTimeout = timeout
+		}
+	}
+}
+
+func WithServerSignals(signals ...os.Signal) ServerOption {
+	return func(s *Server) {
+		if len(signals) > 0 {
+			s.signals = signals
+		}
+	}
+}
+
+// NewServer returns a server of NewDefaultEcho listening on DefaultAddress,
+// which is shut down by SIGINT and SIGTERM.
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		address:      DefaultAddress,
+		drainTimeout: DefaultDrainTimeout,
+		startTimeout: DefaultStartTimeout,
+		signals:      []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		stop:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.echo == nil {
+		s.echo = NewDefaultEcho()
+	}
+	s.echo.HideBanner = true
+	s.echo.HidePort = true
+	return s
+}
+
+func (s *Server) Echo() *echo.Echo {
+	return s.echo
+}
+
+// OnStart adds a hook run before the server listens, the hooks are run in the order they are added.
+func (s *Server) OnStart(name string, fn HookFunc) *Server {
+	s.startHooks = append(s.startHooks, hook{name, fn})
+	return s
+}
+
+// OnStop adds a hook run after the server is shut down, the hooks are run in the reverse order they are added,
+// e.g. closing diskqueues, GORM and Redis. A stop hook is paired with the start hook of the same name,
+// and it is skipped if the start hook is not run or fails.
+func (s *Server) OnStop(name string, fn HookFunc) *Server {
+	s.stopHooks = append(s.stopHooks, hook{name, fn})
+	return s
+}
+
+// IsReady reports whether the start hooks have succeeded and the server is listening.
+// It becomes false once the server starts shutting down.
+func (s *Server) IsReady() bool {
+	return atomic.LoadInt32(&s.ready) == 1
+}
+
+// Check returns ErrServerNotReady if the server is not ready,
+// it is added to the readiness checks of package health while the server runs.
+func (s *Server) Check(ctx context.Context) error {
+	if !s.IsReady() {
+		return ErrServerNotReady
+	}
+	return nil
+}
+
+// Addr returns the address the server listens on, it is nil before the server listens.
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Run runs the start hooks, serves until a signal is received or Stop is called, then drains the requests
+// in flight, runs the stop hooks and flushes the log. The stop hooks of the started components are also run
+// if a start hook fails. The signals are handled from the start, they cancel the start hook in progress.
+func (s *Server) Run() (err error) {
+	defer func() {
+		_ = FlushLog()
+	}()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), s.signals...)
+	defer cancel()
+
+	for i, h := range s.startHooks {
+		if err = s.runStartHook(ctx, h); err != nil {
+			Error("start hook %s failed: %v", h.name, err)
+			s.runStopHooks(s.startHooks[i:])
+			return
+		}
+		Info("start hook %s done", h.name)
+	}
+
+	if s.listener, err = net.Listen("tcp", s.address); err != nil {
+		s.runStopHooks(nil)
+		return
+	}
+	s.echo.Listener = s.listener
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.echo.Start(s.address)
+	}()
+
+	health.AddChecker(ServerCheckName, health.CheckerFunc(s.Check), health.WithCacheTTL(0))
+	defer health.RemoveChecker(ServerCheckName)
+
+	atomic.StoreInt32(&s.ready, 1)
+	Info("server listens on %s", s.listener.Addr())
+
+	select {
+	case <-ctx.Done():
+		Info("server received signal, shutting down")
+	case <-s.stop:
+		Info("server is stopped, shutting down")
+	case err = <-served:
+		Error("server failed: %v", err)
+	}
+	atomic.StoreInt32(&s.ready, 0)
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	defer drainCancel()
+
+	if serr := s.echo.Shutdown(drainCtx); serr != nil {
+		Error("server shutdown failed: %v", serr)
+		if err == nil {
+			err = serr
+		}
+	}
+
+	if herr := s.runStopHooks(nil); err == nil {
+		err = herr
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return
+}
+
+// Stop makes Run shut down the server as if a signal is received.
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// runStartHook runs h within the start timeout, it returns once ctx is done even if h does not.
+func (s *Server) runStartHook(ctx context.Context, h hook) error {
+	ctx, cancel := context.WithTimeout(ctx, s.startTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runStopHooks runs the stop hooks in reverse order within the drain timeout, and returns the first error.
+// The stop hooks paired with notStarted are skipped.
+func (s *Server) runStopHooks(notStarted []hook) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.drainTimeout)
+	defer cancel()
+
+	skipped := make(map[string]struct{}, len(notStarted))
+	for _, h := range notStarted {
+		skipped[h.name] = struct{}{}
+	}
+
+	for i := len(s.stopHooks) - 1; i >= 0; i-- {
+		h := s.stopHooks[i]
+		if _, exists := skipped[h.name]; exists {
+			Info("stop hook %s skipped", h.name)
+			continue
+		}
+		if herr := h.fn(ctx); herr != nil {
+			Error("stop hook %s failed: %v", h.name, herr)
+			if err == nil {
+				err = herr
+			}
+			continue
+		}
+		Info("stop hook %s done", h.name)
+	}
+	return
+}
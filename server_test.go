@@ -0,0 +1,141 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Run(t *testing.T) {
+	var calls []string
+	record := func(name string) HookFunc {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	s := NewServer(WithServerEcho(echo.New()), WithServerAddress("127.0.0.1:0"), WithDrainTimeout(time.Second))
+	s.OnStart("config", record("start config")).
+		OnStart("db", record("start db")).
+		OnStop("db", record("stop db")).
+		OnStop("diskqueue", record("stop diskqueue"))
+	s.Echo().GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	assert.ErrorIs(t, s.Check(context.Background()), ErrServerNotReady)
+
+	done := make(chan error)
+	go func() {
+		done <- s.Run()
+	}()
+
+	assert.Eventually(t, s.IsReady, time.Second, time.Millisecond)
+	assert.Nil(t, s.Check(context.Background()))
+	assert.Equal(t, health.StatusUp, health.Ready(context.Background()).Checks[ServerCheckName].Status)
+
+	resp, err := http.Get("http://" + s.Addr().String() + "/")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	s.Stop()
+	assert.Nil(t, <-done)
+	assert.False(t, s.IsReady())
+	assert.NotContains(t, health.Ready(context.Background()).Checks, ServerCheckName)
+	assert.Equal(t, []string{"start config", "start db", "stop diskqueue", "stop db"}, calls)
+}
+
+func TestServer_StartHookFailed(t *testing.T) {
+	errDB := errors.New("db not found")
+
+	var calls []string
+	record := func(name string) HookFunc {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	s := NewServer(WithServerEcho(echo.New()), WithServerAddress("127.0.0.1:0"))
+	s.OnStart("config", record("start config")).
+		OnStart("db", func(ctx context.Context) error {
+			return errDB
+		}).
+		OnStart("diskqueue", record("start diskqueue")).
+		OnStop("config", record("stop config")).
+		OnStop("db", record("stop db")).
+		OnStop("diskqueue", record("stop diskqueue")).
+		OnStop("log", record("stop log"))
+
+	assert.ErrorIs(t, s.Run(), errDB)
+	// only the components started and those without start hooks are stopped.
+	assert.Equal(t, []string{"start config", "stop log", "stop config"}, calls)
+	assert.False(t, s.IsReady())
+}
+
+func TestServer_ListenFailed(t *testing.T) {
+	var calls []string
+	s := NewServer(WithServerEcho(echo.New()), WithServerAddress("127.0.0.1:-1"))
+	s.OnStart("db", func(ctx context.Context) error {
+		calls = append(calls, "start db")
+		return nil
+	}).OnStop("db", func(ctx context.Context) error {
+		calls = append(calls, "stop db")
+		return nil
+	})
+
+	assert.NotNil(t, s.Run())
+	assert.Equal(t, []string{"start db", "stop db"}, calls)
+}
+
+func TestServer_StartHookTimeout(t *testing.T) {
+	var calls []string
+	s := NewServer(WithServerEcho(echo.New()), WithServerAddress("127.0.0.1:0"), WithStartTimeout(time.Millisecond*20))
+	s.OnStart("config", func(ctx context.Context) error {
+		calls = append(calls, "start config")
+		return nil
+	}).OnStart("db", func(ctx context.Context) error {
+		// the hook hangs regardless of ctx.
+		select {}
+	}).OnStop("config", func(ctx context.Context) error {
+		calls = append(calls, "stop config")
+		return nil
+	})
+
+	assert.ErrorIs(t, s.Run(), context.DeadlineExceeded)
+	assert.Equal(t, []string{"start config", "stop config"}, calls)
+}
+
+func TestServer_SignalDuringStart(t *testing.T) {
+	var calls []string
+	s := NewServer(WithServerEcho(echo.New()), WithServerAddress("127.0.0.1:0"), WithServerSignals(os.Interrupt))
+	s.OnStart("config", func(ctx context.Context) error {
+		calls = append(calls, "start config")
+		return nil
+	}).OnStart("db", func(ctx context.Context) error {
+		p, err := os.FindProcess(os.Getpid())
+		if err != nil {
+			return err
+		}
+		if err = p.Signal(os.Interrupt); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}).OnStop("config", func(ctx context.Context) error {
+		calls = append(calls, "stop config")
+		return nil
+	})
+
+	// the signal cancels the start hook instead of killing the process.
+	assert.ErrorIs(t, s.Run(), context.Canceled)
+	assert.Equal(t, []string{"start config", "stop config"}, calls)
+}
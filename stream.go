@@ -0,0 +1,205 @@
+package echotool
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/json"
+	"github.com/songzhaoliang/echotool/metric"
+	"go.uber.org/zap"
+)
+
+const (
+	MIMETextEventStream = "text/event-stream"
+
+	MStreamEvents   = "stream_events_total"
+	MStreamDuration = "stream_duration_seconds"
+)
+
+var (
+	ErrInvalidEventName = errors.New("event name contains line breaks")
+)
+
+// StreamKeepAliveInterval is the interval of the comments which keep the stream alive.
+var StreamKeepAliveInterval = time.Second * 15
+
+var keepAliveComment = []byte(": keep-alive\n\n")
+
+// SendFunc sends an event of server-sent events.
+// data is sent as it is if it is string or []byte, otherwise it is encoded to json.
+// event must not contain line breaks, or ErrInvalidEventName is returned.
+type SendFunc func(event string, data interface{}) error
+
+type StreamFunc func(send SendFunc) error
+
+// Stream writes server-sent events by fn, and the finisher and aborter are skipped after Stream.
+// Send fails once the client disconnects, then fn should return.
+// The stream is logged and recorded by metrics MStreamEvents and MStreamDuration,
+// which are defined by DefineStreamMetrics.
+// It fails with CodeForbidden and ErrAttributeUnchecked before writing anything if the attribute rules
+// of the policy have not been checked by Authorize, and with CodeInternalErr and ErrContextDetached
+// on a derived Context, which has no response to write.
+func (ec *Context) Stream(fn StreamFunc) (err error) {
+	if ec.echoContext == nil {
+		return AcquireEchotoolError(CodeInternalErr, ErrContextDetached)
+	}
+	if !ec.isAuthorized() {
+		return AcquireEchotoolError(CodeForbidden, ErrAttributeUnchecked)
+	}
+
+	c := ec.echoContext
+	ec.streamed = true
+
+	resp := c.Response()
+	header := resp.Header()
+	header.Set(echo.HeaderContentType, MIMETextEventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set(echo.HeaderConnection, "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(http.StatusOK)
+	resp.Flush()
+
+	var (
+		lock   sync.Mutex
+		events int
+		start  = time.Now()
+		done   = make(chan struct{})
+		wg     sync.WaitGroup
+	)
+
+	write := func(b []byte, isEvent bool) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if err := ec.Err(); err != nil {
+			return err
+		}
+		if _, err := resp.Write(b); err != nil {
+			return err
+		}
+		resp.Flush()
+
+		if isEvent {
+			events++
+		}
+		return nil
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(StreamKeepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if write(keepAliveComment, false) != nil {
+					return
+				}
+			case <-ec.Done():
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	defer func() {
+		close(done)
+		wg.Wait()
+
+		cost := time.Since(start)
+		CtxInfoKV(ec, "stream closed",
+			zap.String("request_id", GetRequestID(c)),
+			zap.String("handler", ec.GetHandlerName()),
+			zap.Int("events", events),
+			zap.Duration("cost", cost),
+			zap.Error(err))
+
+		labels := newStreamLabels(ec.GetHandlerName())
+		_ = metric.EmitCounter(MStreamEvents, float64(events), labels)
+		_ = metric.EmitHistogram(MStreamDuration, cost.Seconds(), labels)
+	}()
+
+	return fn(func(event string, data interface{}) error {
+		b, err := encodeEvent(event, data)
+		if err != nil {
+			return err
+		}
+
+		return write(b, true)
+	})
+}
+
+func (ec *Context) IsStreamed() bool {
+	return ec.streamed
+}
+
+// lineBreaks are the line terminators of server-sent events.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// encodeEvent rejects the event name with line breaks, which could inject other fields or events,
+// and splits data into lines by any line terminator.
+func encodeEvent(event string, data interface{}) ([]byte, error) {
+	if strings.ContainsAny(event, "\r\n") {
+		return nil, ErrInvalidEventName
+	}
+
+	var payload string
+	switch v := data.(type) {
+	case string:
+		payload = v
+	case []byte:
+		payload = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		payload = string(b)
+	}
+
+	var buffer bytes.Buffer
+	if event != "" {
+		buffer.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(lineBreaks.Replace(payload), "\n") {
+		buffer.WriteString("data: " + line + "\n")
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes(), nil
+}
+
+// DefineStreamMetrics defines the metrics recorded by Context.Stream in c.
+func DefineStreamMetrics(c *metric.MetricClient) error {
+	labels := newStreamLabels("")
+	if err := c.DefineCounter(MStreamEvents, labels); err != nil {
+		return err
+	}
+	return c.DefineHistogram(MStreamDuration, labels, metric.DefaultBuckets)
+}
+
+type streamLabels struct {
+	Handler string
+}
+
+var _ metric.LabelsParser = (*streamLabels)(nil)
+
+func newStreamLabels(handler string) *streamLabels {
+	return &streamLabels{
+		Handler: handler,
+	}
+}
+
+func (ls *streamLabels) ParseToLabels() map[string]string {
+	return map[string]string{
+		"handler": ls.Handler,
+	}
+}
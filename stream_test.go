@@ -0,0 +1,78 @@
+package echotool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_Stream(t *testing.T) {
+	interval := StreamKeepAliveInterval
+	StreamKeepAliveInterval = time.Millisecond * 10
+	defer func() {
+		StreamKeepAliveInterval = interval
+	}()
+
+	rec := PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		err := ec.Stream(func(send SendFunc) error {
+			assert.NoError(t, send("greeting", "hello\nworld"))
+			time.Sleep(time.Millisecond * 50)
+			return send("", map[string]int{"id": 1})
+		})
+		assert.NoError(t, err)
+		assert.True(t, ec.IsStreamed())
+	})
+
+	body := rec.Body.String()
+	assert.Equal(t, MIMETextEventStream, rec.Header().Get(echo.HeaderContentType))
+	assert.True(t, strings.HasPrefix(body, "event: greeting\ndata: hello\ndata: world\n\n"))
+	assert.Contains(t, body, string(keepAliveComment))
+	assert.Contains(t, body, "\n\ndata: {\"id\":1}\n\n")
+	assert.NotContains(t, body, "code")
+}
+
+func TestContext_StreamDerived(t *testing.T) {
+	PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		dc, cancel := ec.WithCancel()
+		defer cancel()
+
+		err := dc.Stream(func(send SendFunc) error {
+			t.Fatal("derived context streams")
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrContextDetached)
+		assert.False(t, ec.IsStreamed())
+		ec.Finish(CodeOKZero, nil)
+	})
+}
+
+func TestEncodeEvent(t *testing.T) {
+	_, err := encodeEvent("greeting\ndata: injected", "hello")
+	assert.Equal(t, ErrInvalidEventName, err)
+	_, err = encodeEvent("greeting\r", "hello")
+	assert.Equal(t, ErrInvalidEventName, err)
+
+	b, err := encodeEvent("greeting", "a\r\nb\rc")
+	assert.NoError(t, err)
+	assert.Equal(t, "event: greeting\ndata: a\ndata: b\ndata: c\n\n", string(b))
+}
+
+func TestContext_StreamDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+	PerformRequest(NewEngine(), req, func(c echo.Context, ec *Context) {
+		err := ec.Stream(func(send SendFunc) error {
+			assert.NoError(t, send("", "first"))
+			cancel()
+			return send("", "second")
+		})
+		assert.Equal(t, context.Canceled, err)
+	})
+}
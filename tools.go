@@ -576,6 +576,10 @@ func GetHostname() (host string) {
 }
 
 func GetHandlerName(f HandlerFunc) string {
+	return getFuncName(f)
+}
+
+func getFuncName(f interface{}) string {
 	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
 	return name[strings.LastIndex(name, handy.StrDot)+1:]
 }
@@ -0,0 +1,96 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// Exporter exports the ended spans in batches, which is called by one goroutine of Tracer at a time.
+type Exporter interface {
+	Export(ctx context.Context, spans []*SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// MemoryExporter keeps the spans in memory, which is useful for tests.
+type MemoryExporter struct {
+	lock  sync.Mutex
+	spans []*SpanData
+}
+
+var _ Exporter = (*MemoryExporter)(nil)
+
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+func (e *MemoryExporter) Export(ctx context.Context, spans []*SpanData) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *MemoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// Spans returns the exported spans in the order they ended.
+func (e *MemoryExporter) Spans() []*SpanData {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	spans := make([]*SpanData, len(e.spans))
+	copy(spans, e.spans)
+	return spans
+}
+
+func (e *MemoryExporter) Reset() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.spans = nil
+}
+
+// FileExporter appends the spans to a file in json lines.
+type FileExporter struct {
+	lock sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+var _ Exporter = (*FileExporter)(nil)
+
+func NewFileExporter(path string) (*FileExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileExporter{
+		file: f,
+		enc:  json.NewEncoder(f),
+	}, nil
+}
+
+func (e *FileExporter) Export(ctx context.Context, spans []*SpanData) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for _, span := range spans {
+		if err := e.enc.Encode(span); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shutdown closes the file.
+func (e *FileExporter) Shutdown(ctx context.Context) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.file.Close()
+}
@@ -0,0 +1,261 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOTLPEndpoint = "http://localhost:4318"
+	DefaultOTLPTimeout  = time.Second * 10
+
+	otlpTracesPath = "/v1/traces"
+	scopeName      = "github.com/songzhaoliang/echotool/trace"
+
+	otlpStatusError = 2
+)
+
+// OTLPConfig is the config of the exporter which sends spans to a collector by OTLP/HTTP with json encoding.
+type OTLPConfig struct {
+	// Endpoint is the base url of the collector, to which "/v1/traces" is appended.
+	Endpoint    string
+	Headers     map[string]string
+	Timeout     time.Duration
+	ServiceName string
+	Client      *http.Client
+}
+
+type OTLPOption func(*OTLPConfig)
+
+func WithOTLPEndpoint(endpoint string) OTLPOption {
+	return func(cfg *OTLPConfig) {
+		if endpoint != "" {
+			cfg.Endpoint = strings.TrimSuffix(endpoint, "/")
+		}
+	}
+}
+
+func WithOTLPHeader(key, value string) OTLPOption {
+	return func(cfg *OTLPConfig) {
+		cfg.Headers[key] = value
+	}
+}
+
+func WithOTLPTimeout(timeout time.Duration) OTLPOption {
+	return func(cfg *OTLPConfig) {
+		if timeout > 0 {
+			cfg.Timeout = timeout
+		}
+	}
+}
+
+func WithOTLPClient(client *http.Client) OTLPOption {
+	return func(cfg *OTLPConfig) {
+		if client != nil {
+			cfg.Client = client
+		}
+	}
+}
+
+type OTLPExporter struct {
+	cfg *OTLPConfig
+	url string
+}
+
+var _ Exporter = (*OTLPExporter)(nil)
+
+// NewOTLPExporter returns an exporter whose resource attribute "service.name" is serviceName.
+func NewOTLPExporter(serviceName string, opts ...OTLPOption) *OTLPExporter {
+	cfg := &OTLPConfig{
+		Endpoint:    DefaultOTLPEndpoint,
+		Headers:     make(map[string]string),
+		Timeout:     DefaultOTLPTimeout,
+		ServiceName: serviceName,
+		Client:      http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &OTLPExporter{
+		cfg: cfg,
+		url: cfg.Endpoint + otlpTracesPath,
+	}
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []*SpanData) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("otlp export failed with status %d: %s", resp.StatusCode, msg)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// the types below follow the json encoding of OTLP, in which ids are hex strings.
+
+type otlpRequest struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource      `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []*otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope   `json:"scope"`
+	Spans []*otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	TraceState        string          `json:"traceState,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []*otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func (e *OTLPExporter) request(spans []*SpanData) *otlpRequest {
+	ss := &otlpScopeSpans{
+		Scope: otlpScope{Name: scopeName},
+		Spans: make([]*otlpSpan, 0, len(spans)),
+	}
+	for _, span := range spans {
+		s := &otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			TraceState:        span.TraceState,
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.Error != "" {
+			s.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+		ss.Spans = append(ss.Spans, s)
+	}
+
+	return &otlpRequest{
+		ResourceSpans: []*otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: otlpAttributes(map[string]interface{}{"service.name": e.cfg.ServiceName}),
+			},
+			ScopeSpans: []*otlpScopeSpans{ss},
+		}},
+	}
+}
+
+func otlpAttributes(attributes map[string]interface{}) []*otlpKeyValue {
+	kvs := make([]*otlpKeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		kvs = append(kvs, &otlpKeyValue{Key: k, Value: newOTLPValue(v)})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	return kvs
+}
+
+func newOTLPValue(v interface{}) (value otlpValue) {
+	switch v := v.(type) {
+	case bool:
+		value.BoolValue = &v
+	case int:
+		value.IntValue = formatInt(int64(v))
+	case int32:
+		value.IntValue = formatInt(int64(v))
+	case int64:
+		value.IntValue = formatInt(v)
+	case uint32:
+		value.IntValue = formatInt(int64(v))
+	case float32:
+		f := float64(v)
+		value.DoubleValue = &f
+	case float64:
+		value.DoubleValue = &v
+	case string:
+		value.StringValue = &v
+	default:
+		s := fmt.Sprint(v)
+		value.StringValue = &s
+	}
+	return
+}
+
+func formatInt(i int64) *string {
+	s := strconv.FormatInt(i, 10)
+	return &s
+}
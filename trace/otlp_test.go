@@ -0,0 +1,109 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collector is a stand-in of the OTLP/HTTP collector.
+type collector struct {
+	lock     sync.Mutex
+	requests []*otlpRequest
+	headers  []http.Header
+	status   int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	req := &otlpRequest{}
+	if err := json.Unmarshal(body, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header)
+
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+		_, _ = w.Write([]byte("unavailable"))
+		return
+	}
+	_, _ = w.Write([]byte("{}"))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	exporter := NewOTLPExporter("user-service",
+		WithOTLPEndpoint(server.URL+"/"),
+		WithOTLPHeader("Authorization", "Bearer token"))
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "GetUser", WithKind(KindServer))
+	root.SetAttribute("http.status_code", 500)
+	root.SetAttribute("slow", true)
+	_, child := Start(ctx, "redis get", WithKind(KindClient), WithAttribute("db.system", "redis"))
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	root.End()
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+
+	assert.Len(t, c.requests, 1)
+	assert.Equal(t, "Bearer token", c.headers[0].Get("Authorization"))
+
+	rs := c.requests[0].ResourceSpans[0]
+	assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	assert.Equal(t, "user-service", *rs.Resource.Attributes[0].Value.StringValue)
+
+	spans := rs.ScopeSpans[0].Spans
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "redis get", spans[0].Name)
+	assert.Equal(t, KindClient, spans[0].Kind)
+	assert.Equal(t, otlpStatusError, spans[0].Status.Code)
+	assert.Equal(t, "timeout", spans[0].Status.Message)
+	assert.Equal(t, root.SpanContext().TraceID.String(), spans[0].TraceID)
+	assert.Equal(t, root.SpanContext().SpanID.String(), spans[0].ParentSpanID)
+
+	assert.Equal(t, "GetUser", spans[1].Name)
+	assert.Equal(t, "", spans[1].ParentSpanID)
+	assert.Equal(t, []*otlpKeyValue{
+		{Key: "http.status_code", Value: otlpValue{IntValue: formatInt(500)}},
+		{Key: "slow", Value: newOTLPValue(true)},
+	}, spans[1].Attributes)
+}
+
+func TestOTLPExporter_Error(t *testing.T) {
+	c := &collector{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	var errs []error
+	tracer := NewTracer(NewOTLPExporter("user-service", WithOTLPEndpoint(server.URL)),
+		WithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+	_, span := tracer.Start(context.Background(), "GetUser")
+	span.End()
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "503")
+}
@@ -0,0 +1,163 @@
+package trace
+
+import (
+	"sync"
+	"time"
+)
+
+// SpanKind is the kind of span, whose values are the same as OTLP.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = iota + 1
+	KindServer
+	KindClient
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// Span is an operation of a trace, which is exported once End is called if it is sampled.
+// All methods are safe to be called on a nil *Span, which does nothing.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+	parent SpanID
+	name   string
+	kind   SpanKind
+	start  time.Time
+
+	lock       sync.Mutex
+	attributes map[string]interface{}
+	err        string
+	ended      bool
+}
+
+type StartOption func(*Span)
+
+func WithKind(kind SpanKind) StartOption {
+	return func(s *Span) {
+		s.kind = kind
+	}
+}
+
+// WithStartTime sets the start time of the span, which is now by default.
+func WithStartTime(t time.Time) StartOption {
+	return func(s *Span) {
+		if !t.IsZero() {
+			s.start = t
+		}
+	}
+}
+
+func WithAttribute(key string, value interface{}) StartOption {
+	return func(s *Span) {
+		s.attributes[key] = value
+	}
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+func (s *Span) Tracer() *Tracer {
+	if s == nil {
+		return nil
+	}
+	return s.tracer
+}
+
+// SetAttribute sets an attribute, whose value should be string, bool, integer or float.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.attributes[key] = value
+}
+
+// RecordError marks the span failed by err, nil is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.err = err.Error()
+}
+
+// End ends the span, only the first call takes effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	end := time.Now()
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	s.ended = true
+	data := s.data(end)
+	s.lock.Unlock()
+
+	if s.sc.IsSampled() {
+		s.tracer.enqueue(data)
+	}
+}
+
+func (s *Span) data(end time.Time) *SpanData {
+	attributes := make(map[string]interface{}, len(s.attributes))
+	for k, v := range s.attributes {
+		attributes[k] = v
+	}
+
+	d := &SpanData{
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		TraceState: s.sc.State,
+		Name:       s.name,
+		Kind:       s.kind,
+		Start:      s.start,
+		End:        end,
+		Attributes: attributes,
+		Error:      s.err,
+	}
+	if s.parent.IsValid() {
+		d.ParentSpanID = s.parent.String()
+	}
+	return d
+}
+
+// SpanData is the snapshot of an ended span passed to Exporter.
+type SpanData struct {
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	TraceState   string                 `json:"trace_state,omitempty"`
+	Name         string                 `json:"name"`
+	Kind         SpanKind               `json:"kind"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+func (d *SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
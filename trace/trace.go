@@ -0,0 +1,187 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTracestate  = "tracestate"
+
+	FlagSampled = 0x01
+
+	maxTracestateLen = 512
+)
+
+var (
+	ErrInvalidTraceparent = errors.New("invalid traceparent")
+)
+
+type TraceID [16]byte
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+type SpanID [8]byte
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext is the part of a span which is propagated across processes by traceparent and tracestate.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	State   string
+	Remote  bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// Traceparent returns the value of header traceparent in version 00.
+func (sc SpanContext) Traceparent() string {
+	var b strings.Builder
+	b.Grow(55)
+	b.WriteString("00-")
+	b.WriteString(sc.TraceID.String())
+	b.WriteString("-")
+	b.WriteString(sc.SpanID.String())
+	b.WriteString("-")
+	b.WriteString(hex.EncodeToString([]byte{sc.Flags}))
+	return b.String()
+}
+
+// ParseTraceparent parses the value of header traceparent, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// The fields appended by the future versions are ignored.
+func ParseTraceparent(s string) (sc SpanContext, err error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return sc, ErrInvalidTraceparent
+	}
+
+	version, ok := decodeHex(parts[0], 1)
+	if !ok || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, ErrInvalidTraceparent
+	}
+
+	traceID, ok := decodeHex(parts[1], len(sc.TraceID))
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	spanID, ok := decodeHex(parts[2], len(sc.SpanID))
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+	flags, ok := decodeHex(parts[3], 1)
+	if !ok {
+		return sc, ErrInvalidTraceparent
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex decodes s of n bytes, which must be in lower case.
+func decodeHex(s string, n int) ([]byte, bool) {
+	if len(s) != n*2 || strings.ToLower(s) != s {
+		return nil, false
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+// Extract returns the remote span context carried by header, ok is false if traceparent is missing or invalid.
+// tracestate is kept as it is if traceparent is valid.
+func Extract(header http.Header) (sc SpanContext, ok bool) {
+	v := header.Get(HeaderTraceparent)
+	if v == "" {
+		return
+	}
+
+	sc, err := ParseTraceparent(v)
+	if err != nil {
+		return
+	}
+
+	state := strings.Join(header.Values(HeaderTracestate), ",")
+	if len(state) <= maxTracestateLen {
+		sc.State = state
+	}
+	sc.Remote = true
+	return sc, true
+}
+
+// Inject sets traceparent and tracestate of the span in ctx to header, for the requests to downstream.
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+
+	header.Set(HeaderTraceparent, sc.Traceparent())
+	if sc.State != "" {
+		header.Set(HeaderTracestate, sc.State)
+	} else {
+		header.Del(HeaderTracestate)
+	}
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the span in ctx, or nil if there is none.
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext makes the spans started with ctx children of sc, which comes from Extract.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the span in ctx, or the remote one.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	if ctx != nil {
+		if sc, ok := ctx.Value(remoteKey{}).(SpanContext); ok {
+			return sc
+		}
+	}
+	return SpanContext{}
+}
@@ -0,0 +1,71 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"future version", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"version 00 with extra", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"version ff", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"upper case", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false},
+		{"bad flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sc, err := ParseTraceparent(c.value)
+			if !c.valid {
+				assert.Equal(t, ErrInvalidTraceparent, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+			assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+			assert.True(t, sc.IsSampled())
+			assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+		})
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	_, ok := Extract(header)
+	assert.False(t, ok)
+
+	header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	header.Add(HeaderTracestate, "rojo=00f067aa0ba902b7")
+	header.Add(HeaderTracestate, "congo=t61rcWkgMzE")
+	sc, ok := Extract(header)
+	assert.True(t, ok)
+	assert.True(t, sc.Remote)
+	assert.False(t, sc.IsSampled())
+	assert.Equal(t, "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE", sc.State)
+
+	tracer := NewTracer(NewMemoryExporter())
+	defer tracer.Shutdown(context.Background())
+
+	ctx, span := tracer.Start(ContextWithRemoteSpanContext(context.Background(), sc), "child")
+	out := http.Header{}
+	Inject(ctx, out)
+	assert.Equal(t, span.SpanContext().Traceparent(), out.Get(HeaderTraceparent))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID.String())
+	assert.Equal(t, sc.State, out.Get(HeaderTracestate))
+
+	out = http.Header{}
+	Inject(context.Background(), out)
+	assert.Empty(t, out)
+}
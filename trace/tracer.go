@@ -0,0 +1,295 @@
+package trace
+
+import (
+	"context"
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultQueueSize    = 2048
+	DefaultBatchSize    = 512
+	DefaultBatchTimeout = time.Second * 5
+)
+
+// TracerConfig is the config of Tracer, the spans are exported in batches in background.
+type TracerConfig struct {
+	// SampleRatio is the ratio of the sampled traces started by the tracer, 1 by default.
+	// The traces continued from remote parents follow the sampled flag of the parents.
+	SampleRatio  float64
+	QueueSize    int
+	BatchSize    int
+	BatchTimeout time.Duration
+	// ErrorHandler is called if the exporter fails, the errors are ignored by default.
+	ErrorHandler func(error)
+}
+
+type TracerOption func(*TracerConfig)
+
+func WithSampleRatio(ratio float64) TracerOption {
+	return func(cfg *TracerConfig) {
+		if ratio >= 0 && ratio <= 1 {
+			cfg.SampleRatio = ratio
+		}
+	}
+}
+
+func WithQueueSize(size int) TracerOption {
+	return func(cfg *TracerConfig) {
+		if size > 0 {
+			cfg.QueueSize = size
+		}
+	}
+}
+
+func WithBatchSize(size int) TracerOption {
+	return func(cfg *TracerConfig) {
+		if size > 0 {
+			cfg.BatchSize = size
+		}
+	}
+}
+
+func WithBatchTimeout(timeout time.Duration) TracerOption {
+	return func(cfg *TracerConfig) {
+		if timeout > 0 {
+			cfg.BatchTimeout = timeout
+		}
+	}
+}
+
+func WithErrorHandler(handler func(error)) TracerOption {
+	return func(cfg *TracerConfig) {
+		cfg.ErrorHandler = handler
+	}
+}
+
+type Tracer struct {
+	cfg      *TracerConfig
+	exporter Exporter
+
+	queue   chan *SpanData
+	flushCh chan chan struct{}
+	stop    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	dropped uint64
+
+	randLock sync.Mutex
+	rand     *rand.Rand
+}
+
+// NewTracer returns a tracer which exports the sampled spans by exporter.
+// Shutdown should be called before exit, otherwise the spans in queue are lost.
+func NewTracer(exporter Exporter, opts ...TracerOption) *Tracer {
+	cfg := &TracerConfig{
+		SampleRatio:  1,
+		QueueSize:    DefaultQueueSize,
+		BatchSize:    DefaultBatchSize,
+		BatchTimeout: DefaultBatchTimeout,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	t := &Tracer{
+		cfg:      cfg,
+		exporter: exporter,
+		queue:    make(chan *SpanData, cfg.QueueSize),
+		flushCh:  make(chan chan struct{}),
+		stop:     make(chan struct{}),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	t.wg.Add(1)
+	go t.loop()
+	return t
+}
+
+// Start starts a span which is a child of the span or the remote span context in ctx,
+// and returns a copy of ctx carrying the span.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	span := &Span{
+		tracer:     t,
+		name:       name,
+		kind:       KindInternal,
+		start:      time.Now(),
+		attributes: make(map[string]interface{}),
+	}
+	for _, opt := range opts {
+		opt(span)
+	}
+
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		span.sc = SpanContext{
+			TraceID: parent.TraceID,
+			Flags:   parent.Flags,
+			State:   parent.State,
+		}
+		span.parent = parent.SpanID
+	} else {
+		span.sc.TraceID = t.newTraceID()
+		if t.sample(span.sc.TraceID) {
+			span.sc.Flags = FlagSampled
+		}
+	}
+	span.sc.SpanID = t.newSpanID()
+
+	return ContextWithSpan(ctx, span), span
+}
+
+// Start starts a child of the span in ctx by the tracer of that span.
+// It returns ctx and a nil span if ctx carries no span, so the caller does nothing without tracing.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, opts...)
+}
+
+// Dropped returns the number of spans dropped since the queue is full or the tracer is shut down.
+func (t *Tracer) Dropped() uint64 {
+	return atomic.LoadUint64(&t.dropped)
+}
+
+// Flush exports the spans in queue and waits until they are exported.
+func (t *Tracer) Flush(ctx context.Context) error {
+	ch := make(chan struct{})
+	select {
+	case t.flushCh <- ch:
+	case <-t.stop:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the spans in queue, then shuts down the exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	t.once.Do(func() {
+		close(t.stop)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+func (t *Tracer) enqueue(d *SpanData) {
+	select {
+	case <-t.stop:
+		atomic.AddUint64(&t.dropped, 1)
+		return
+	default:
+	}
+
+	select {
+	case t.queue <- d:
+	default:
+		atomic.AddUint64(&t.dropped, 1)
+	}
+}
+
+func (t *Tracer) loop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(t.cfg.BatchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]*SpanData, 0, t.cfg.BatchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.Export(context.Background(), batch); err != nil && t.cfg.ErrorHandler != nil {
+			t.cfg.ErrorHandler(err)
+		}
+		batch = make([]*SpanData, 0, t.cfg.BatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case d := <-t.queue:
+				if batch = append(batch, d); len(batch) >= t.cfg.BatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case d := <-t.queue:
+			if batch = append(batch, d); len(batch) >= t.cfg.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ch := <-t.flushCh:
+			drain()
+			close(ch)
+		case <-t.stop:
+			drain()
+			return
+		}
+	}
+}
+
+// sample decides by the trace id, so that the same trace is sampled likewise by the ratio.
+func (t *Tracer) sample(id TraceID) bool {
+	switch {
+	case t.cfg.SampleRatio >= 1:
+		return true
+	case t.cfg.SampleRatio <= 0:
+		return false
+	}
+	return binary.BigEndian.Uint64(id[8:])>>1 < uint64(t.cfg.SampleRatio*(1<<63))
+}
+
+func (t *Tracer) newTraceID() (id TraceID) {
+	t.randLock.Lock()
+	defer t.randLock.Unlock()
+
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], t.rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], t.rand.Uint64())
+	}
+	return
+}
+
+func (t *Tracer) newSpanID() (id SpanID) {
+	t.randLock.Lock()
+	defer t.randLock.Unlock()
+
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], t.rand.Uint64())
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package trace
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracer_Start(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root", WithKind(KindServer), WithAttribute("k", "v"))
+	assert.True(t, root.SpanContext().IsSampled())
+
+	_, child := Start(ctx, "child")
+	child.RecordError(errors.New("failed"))
+	child.End()
+	root.End()
+	root.End()
+
+	_, none := Start(context.Background(), "none")
+	assert.Nil(t, none)
+	none.SetAttribute("k", "v")
+	none.End()
+
+	assert.Nil(t, tracer.Flush(context.Background()))
+	spans := exporter.Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "failed", spans[0].Error)
+	assert.Equal(t, root.SpanContext().SpanID.String(), spans[0].ParentSpanID)
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Equal(t, "", spans[1].ParentSpanID)
+	assert.Equal(t, KindServer, spans[1].Kind)
+	assert.Equal(t, "v", spans[1].Attributes["k"])
+
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+	_, span := tracer.Start(context.Background(), "after shutdown")
+	span.End()
+	assert.Equal(t, uint64(1), tracer.Dropped())
+}
+
+func TestTracer_Sample(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer(exporter, WithSampleRatio(0))
+
+	_, span := tracer.Start(context.Background(), "unsampled")
+	assert.True(t, span.SpanContext().IsValid())
+	assert.False(t, span.SpanContext().IsSampled())
+	span.End()
+
+	// the remote parent decides.
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span = tracer.Start(ContextWithRemoteSpanContext(context.Background(), sc), "sampled")
+	span.End()
+
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "sampled", spans[0].Name)
+
+	tracer = NewTracer(exporter, WithSampleRatio(0.5))
+	defer tracer.Shutdown(context.Background())
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		if _, span := tracer.Start(context.Background(), "ratio"); span.SpanContext().IsSampled() {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 500, sampled, 100)
+}
+
+func TestTracer_Batch(t *testing.T) {
+	exporter := NewMemoryExporter()
+	tracer := NewTracer(exporter, WithBatchSize(2), WithBatchTimeout(time.Hour))
+	defer tracer.Shutdown(context.Background())
+
+	for i := 0; i < 3; i++ {
+		_, span := tracer.Start(context.Background(), "batch")
+		span.End()
+	}
+	assert.Eventually(t, func() bool {
+		return len(exporter.Spans()) == 2
+	}, time.Second, time.Millisecond*10)
+
+	assert.Nil(t, tracer.Flush(context.Background()))
+	assert.Len(t, exporter.Spans(), 3)
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	exporter, err := NewFileExporter(path)
+	assert.Nil(t, err)
+
+	tracer := NewTracer(exporter)
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := Start(ctx, "child", WithStartTime(time.Now().Add(-time.Second)))
+	child.End()
+	root.End()
+	assert.Nil(t, tracer.Shutdown(context.Background()))
+
+	f, err := os.Open(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	var spans []*SpanData
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		span := &SpanData{}
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), span))
+		spans = append(spans, span)
+	}
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.GreaterOrEqual(t, spans[0].Duration(), time.Second)
+}
@@ -0,0 +1,57 @@
+package echotool
+
+import (
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/songzhaoliang/echotool/trace"
+)
+
+const (
+	LogFieldTraceID = "trace_id"
+	LogFieldSpanID  = "span_id"
+)
+
+// WithTracing makes the engine start a server span by tracer for each handler, which continues the trace
+// of header traceparent. The span is carried by the request context, so the spans started with Context,
+// such as those of GORMLogger and RedisLogger, are its children. trace_id and span_id are logged as
+// custom values, and trace.Inject propagates the trace to downstream.
+func WithTracing(tracer *trace.Tracer) Option {
+	return func(e *Engine) {
+		e.tracer = tracer
+	}
+}
+
+func (e *Engine) startSpan(c echo.Context, ec *Context, handlerName string) *trace.Span {
+	req := c.Request()
+	ctx := req.Context()
+	if sc, ok := trace.Extract(req.Header); ok {
+		ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+	}
+
+	ctx, span := e.tracer.Start(ctx, handlerName,
+		trace.WithKind(trace.KindServer),
+		trace.WithStartTime(ec.GetStartTime()),
+		trace.WithAttribute("http.method", req.Method),
+		trace.WithAttribute("http.route", c.Path()),
+		trace.WithAttribute("http.target", req.URL.RequestURI()))
+	c.SetRequest(req.WithContext(ctx))
+
+	sc := span.SpanContext()
+	ec.SetCustomValue(LogFieldTraceID, sc.TraceID.String())
+	ec.SetCustomValue(LogFieldSpanID, sc.SpanID.String())
+	return span
+}
+
+func (e *Engine) endSpan(c echo.Context, ec *Context, span *trace.Span) {
+	span.SetAttribute("code", ec.GetCode())
+	span.SetAttribute("http.status_code", c.Response().Status)
+	if !ec.IsOK() {
+		err := ec.GetError()
+		if err == nil {
+			err = errors.New(CodeMsg(ec.GetCode()))
+		}
+		span.RecordError(err)
+	}
+	span.End()
+}
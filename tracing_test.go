@@ -0,0 +1,106 @@
+package echotool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/songzhaoliang/echotool/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_WithTracing(t *testing.T) {
+	logs := observeLogs(t)
+	exporter := trace.NewMemoryExporter()
+	tracer := trace.NewTracer(exporter)
+	defer tracer.Shutdown(context.Background())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1?verbose=true", nil)
+	req.Header.Set(trace.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(trace.HeaderTracestate, "rojo=00f067aa0ba902b7")
+
+	var outgoing http.Header
+	PerformRequest(NewEngine(WithTracing(tracer)), req, func(c echo.Context, ec *Context) {
+		CtxInfo(ec, "get user")
+
+		NewDefaultGORMLogger().Trace(ec, time.Now().Add(-time.Millisecond), func() (string, int64) {
+			return "SELECT * FROM users WHERE id = 1", 1
+		}, nil)
+
+		cmd := redis.NewStringCmd(ec, "get", "user:1")
+		hook := NewRedisLogger("debug").ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+			outgoing = http.Header{}
+			trace.Inject(ctx, outgoing)
+			return errors.New("i/o timeout")
+		})
+		_ = hook(ec, cmd)
+
+		ec.Abort(CodeNotFound, errors.New("user not found"))
+	})
+	assert.Nil(t, tracer.Flush(context.Background()))
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 3)
+	gormSpan, redisSpan, serverSpan := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.ParentSpanID)
+	assert.Equal(t, "rojo=00f067aa0ba902b7", serverSpan.TraceState)
+	assert.Equal(t, trace.KindServer, serverSpan.Kind)
+	assert.Equal(t, "user not found", serverSpan.Error)
+	assert.Equal(t, http.MethodGet, serverSpan.Attributes["http.method"])
+	assert.Equal(t, "/users/1?verbose=true", serverSpan.Attributes["http.target"])
+	assert.Equal(t, CodeNotFound, serverSpan.Attributes["code"])
+	assert.Equal(t, http.StatusNotFound, serverSpan.Attributes["http.status_code"])
+
+	assert.Equal(t, "gorm SELECT", gormSpan.Name)
+	assert.Equal(t, serverSpan.SpanID, gormSpan.ParentSpanID)
+	assert.Equal(t, int64(1), gormSpan.Attributes["db.rows_affected"])
+	assert.Equal(t, "", gormSpan.Error)
+
+	assert.Equal(t, "redis get", redisSpan.Name)
+	assert.Equal(t, "get user:1", redisSpan.Attributes["db.statement"])
+	assert.Equal(t, serverSpan.SpanID, redisSpan.ParentSpanID)
+	assert.Equal(t, "i/o timeout", redisSpan.Error)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+redisSpan.SpanID+"-01", outgoing.Get(trace.HeaderTraceparent))
+
+	entries := logs.FilterMessage("get user").All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, serverSpan.TraceID, entries[0].ContextMap()[LogFieldTraceID])
+	assert.Equal(t, serverSpan.SpanID, entries[0].ContextMap()[LogFieldSpanID])
+}
+
+func TestRedisStatement(t *testing.T) {
+	ctx := context.Background()
+	for statement, cmd := range map[string]redis.Cmder{
+		"set user:1":     redis.NewStatusCmd(ctx, "set", "user:1", "secret", "ex", 10),
+		"hset user:1":    redis.NewIntCmd(ctx, "hset", "user:1", "token", "secret"),
+		"auth":           redis.NewStatusCmd(ctx, "auth", "user", "secret"),
+		"ping":           redis.NewStatusCmd(ctx, "ping"),
+		"cluster info":   redis.NewStringCmd(ctx, "cluster", "info"),
+		"evalsha user:1": redis.NewCmd(ctx, "evalsha", "sha", 1, "user:1", "secret"),
+		"eval":           redis.NewCmd(ctx, "eval", "return ARGV[1]", 0, "secret"),
+	} {
+		assert.Equal(t, statement, redisStatement(cmd))
+	}
+}
+
+func TestEngine_WithoutTracing(t *testing.T) {
+	observeLogs(t)
+
+	PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		NewDefaultGORMLogger().Trace(ec, time.Now(), func() (string, int64) {
+			return "SELECT 1", 1
+		}, nil)
+
+		_, exists := ec.GetCustomValue(LogFieldTraceID)
+		assert.False(t, exists)
+		assert.Nil(t, trace.SpanFromContext(ec))
+		ec.Finish(CodeOK, nil)
+	})
+}
@@ -0,0 +1,79 @@
+package echotool
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrValueNotFound       = errors.New("value is not found")
+	ErrValueTypeNotMatches = errors.New("value type not matches")
+)
+
+// Set stores value by key for the request. Unlike custom values, it is never printed in logs.
+// It is safe to be called concurrently, and the Contexts derived from ec get a copy of the values.
+func (ec *Context) Set(key string, value interface{}) {
+	ec.valuesLock.Lock()
+	defer ec.valuesLock.Unlock()
+
+	if ec.values == nil {
+		ec.values = make(map[string]interface{})
+	}
+	ec.values[key] = value
+}
+
+func (ec *Context) Get(key string) (value interface{}, exists bool) {
+	ec.valuesLock.RLock()
+	defer ec.valuesLock.RUnlock()
+
+	value, exists = ec.values[key]
+	return
+}
+
+func (ec *Context) Delete(key string) {
+	ec.valuesLock.Lock()
+	defer ec.valuesLock.Unlock()
+
+	delete(ec.values, key)
+}
+
+// copyValues returns a copy of the values, the values themselves are not copied.
+func (ec *Context) copyValues() map[string]interface{} {
+	ec.valuesLock.RLock()
+	defer ec.valuesLock.RUnlock()
+
+	if len(ec.values) == 0 {
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(ec.values))
+	for k, v := range ec.values {
+		values[k] = v
+	}
+	return values
+}
+
+// Get returns the value of key stored by Context.Set, ok is false if it does not exist or is not T.
+func Get[T any](ec *Context, key string) (value T, ok bool) {
+	v, exists := ec.Get(key)
+	if !exists {
+		return
+	}
+
+	value, ok = v.(T)
+	return
+}
+
+// MustGet panics *EchotoolError with CodeInternalErr if the value of key does not exist or is not T.
+func MustGet[T any](ec *Context, key string) T {
+	v, exists := ec.Get(key)
+	if !exists {
+		panic(AcquireEchotoolError(CodeInternalErr, fmt.Errorf("%w: %s", ErrValueNotFound, key)))
+	}
+
+	value, ok := v.(T)
+	if !ok {
+		panic(AcquireEchotoolError(CodeInternalErr, fmt.Errorf("%w: %s is %T", ErrValueTypeNotMatches, key, v)))
+	}
+	return value
+}
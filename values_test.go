@@ -0,0 +1,105 @@
+package echotool
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type valueUser struct {
+	ID   int64
+	Name string
+}
+
+func TestContext_Values(t *testing.T) {
+	logs := observeLogs(t)
+
+	PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		ec.Set("user", &valueUser{ID: 1, Name: "peter"})
+		ec.Set("tenant", "t1")
+	}, func(c echo.Context, ec *Context) {
+		user, ok := Get[*valueUser](ec, "user")
+		assert.True(t, ok)
+		assert.Equal(t, "peter", user.Name)
+
+		_, ok = Get[int](ec, "tenant")
+		assert.False(t, ok)
+		_, ok = Get[string](ec, "missing")
+		assert.False(t, ok)
+		assert.Equal(t, "t1", MustGet[string](ec, "tenant"))
+
+		ec.Delete("tenant")
+		_, exists := ec.Get("tenant")
+		assert.False(t, exists)
+
+		CtxInfo(ec, "values are not logged")
+		ec.Finish(CodeOK, nil)
+	})
+
+	entries := logs.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Empty(t, entries[0].ContextMap())
+
+	ec := &Context{}
+	ec.Set("user", &valueUser{ID: 1})
+	ec.reset()
+	_, exists := ec.Get("user")
+	assert.False(t, exists)
+}
+
+func TestMustGet(t *testing.T) {
+	cases := []struct {
+		name   string
+		target error
+	}{
+		{"missing", ErrValueNotFound},
+		{"tenant", ErrValueTypeNotMatches},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var err error
+			PerformRequest(NewEngine(WithAborter(func(_ echo.Context, ec *Context) {
+				assert.Equal(t, CodeInternalErr, ec.GetCode())
+				err = ec.GetError()
+			})), httptest.NewRequest(http.MethodGet, "/", nil), func(_ echo.Context, ec *Context) {
+				ec.Set("tenant", "t1")
+				MustGet[int](ec, c.name)
+			})
+			assert.True(t, errors.Is(err, c.target))
+		})
+	}
+}
+
+func TestContext_ValuesDerived(t *testing.T) {
+	var derived *Context
+	PerformRequest(NewEngine(), httptest.NewRequest(http.MethodGet, "/", nil), func(c echo.Context, ec *Context) {
+		ec.Set("user", &valueUser{ID: 1})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = Get[*valueUser](ec, "user")
+			}()
+		}
+		ec.Set("tenant", "t1")
+		wg.Wait()
+
+		derived = ec.WithValue("k", "v")
+		derived.Set("tenant", "t2")
+		assert.Equal(t, "t1", MustGet[string](ec, "tenant"))
+		ec.Finish(CodeOK, nil)
+	})
+
+	// the derived Context keeps its values after the pooled one is released.
+	user, ok := Get[*valueUser](derived, "user")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), user.ID)
+	assert.Equal(t, "t2", MustGet[string](derived, "tenant"))
+}